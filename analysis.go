@@ -8,7 +8,7 @@ package tenable
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 )
 
 // AnalysisService handles users for the Tenable instance / API.
@@ -19,80 +19,64 @@ type AnalysisService struct {
 }
 
 type Severity struct {
-	ID          interface{} `json:"id,omitempty"`
-	Name        string      `json:"name,omitempty"`
-	Description string      `json:"description,omitempty"`
-}
-
-type VPRContext struct {
-	ID    interface{} `json:"id,omitempty"`
-	Name  string      `json:"name,omitempty"`
-	Value string      `json:"value,omitempty"`
-	Type  string      `json:"type,omitempty"`
+	ID          ID     `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type Family struct {
-	ID   interface{} `json:"id,omitempty"`
-	Name string      `json:"name,omitempty"`
-	Type string      `json:"type,omitempty"`
+	ID   ID     `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
 }
 
 type Analysis struct {
-	PluginID       string      `json:"pluginID"`
-	Severity       Severity    `json:"severity,omitempty"`
-	VPRScore       string      `json:"vprScore,omitempty"`
-	VPRContext     interface{} `json:"vprContext,omitempty"`
-	IP             string      `json:"ip,omitempty"`
-	UUID           string      `json:"uuid,omitempty"`
-	Port           string      `json:"port,omitempty"`
-	Protocol       string      `json:"protocol,omitempty"`
-	Name           string      `json:"name,omitempty"`
-	DNSName        string      `json:"dnsName,omitempty"`
-	MACAddress     string      `json:"macAddress,omitempty"`
-	NetBiosName    string      `json:"netBiosName,omitempty"`
-	Uniqueness     string      `json:"uniqueness,omitempty"`
-	HostUniqueness string      `json:"hostUniqueness,omitempty"`
-	Family         Family      `json:"family,omitempty"`
-	Repository     Repository  `json:"repository,omitempty"`
-	PluginInfo     string      `json:"pluginInfo,omitempty"`
+	PluginID       ID         `json:"pluginID"`
+	Severity       Severity   `json:"severity,omitempty"`
+	VPRScore       Float      `json:"vprScore,omitempty"`
+	VPRContext     VPRDrivers `json:"vprContext,omitempty"`
+	IP             string     `json:"ip,omitempty"`
+	UUID           string     `json:"uuid,omitempty"`
+	Port           Int        `json:"port,omitempty"`
+	Protocol       string     `json:"protocol,omitempty"`
+	Name           string     `json:"name,omitempty"`
+	DNSName        string     `json:"dnsName,omitempty"`
+	MACAddress     string     `json:"macAddress,omitempty"`
+	NetBiosName    string     `json:"netBiosName,omitempty"`
+	Uniqueness     string     `json:"uniqueness,omitempty"`
+	HostUniqueness string     `json:"hostUniqueness,omitempty"`
+	Family         Family     `json:"family,omitempty"`
+	Repository     Repository `json:"repository,omitempty"`
+	PluginInfo     string     `json:"pluginInfo,omitempty"`
 }
 
-type AnalysisResultSet struct {
-	TotalRecords             string     `json:"totalRecords,omitempty"`
-	ReturnedRecords          int64      `json:"returnedRecords,omitempty"`
-	StartOffset              string     `json:"startOffset,omitempty"`
-	EndOffset                string     `json:"endOffset,omitempty"`
-	MatchingDataElementCount string     `json:"MatchingDataElementCount,omitempty"`
-	Results                  []Analysis `json:"results,omitempty"`
+// ResultSet is the response of an analysis query, T being the result type of the queried tool.
+type ResultSet[T any] struct {
+	TotalRecords             Int `json:"totalRecords,omitempty"`
+	ReturnedRecords          Int `json:"returnedRecords,omitempty"`
+	StartOffset              Int `json:"startOffset,omitempty"`
+	EndOffset                Int `json:"endOffset,omitempty"`
+	MatchingDataElementCount Int `json:"matchingDataElementCount,omitempty"`
+	Results                  []T `json:"results,omitempty"`
 }
 
-// Analysis represents a Tenable user.
-type AnalysisResponse struct {
-	Type      string            `json:",type,omitempty"`
-	Response  AnalysisResultSet `json:"response,omitempty"`
-	ErrorCode int               `json:"error_code,omitempty"`
-	ErrorMsg  string            `json:"error_msg,omitempty"`
-	Warnings  []string          `json:"warnings,omitempty"`
-	Timestamp int               `json:"timestamp,omitempty"`
+// pageValues returns the offsets and total of the result set.
+func (rs *ResultSet[T]) pageValues() (startAt, maxResults, total int, ok bool) {
+	return int(rs.StartOffset), int(rs.EndOffset - rs.StartOffset), int(rs.TotalRecords), true
 }
 
-// PostWithContext gets user info from Tenable using its Account Id
+// AnalysisResultSet is the result set of the listvuln tool.
+type AnalysisResultSet = ResultSet[Analysis]
+
+// AnalysisResponse represents a Tenable analysis response.
+type AnalysisResponse = Envelope[AnalysisResultSet]
+
+// PostWithContext gets user info from Tenable using its Account Id.
+// The body is sent as is, see Query for a typed query that is validated and shaped for its source type.
 //
 // Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
 func (s *AnalysisService) PostWithContext(ctx context.Context, body interface{}) (*AnalysisResponse, *Response, error) {
-	apiEndpoint := fmt.Sprintf("/rest/analysis")
-	fmt.Printf("url: %s\n", apiEndpoint)
-	req, err := s.client.NewRequestWithContext(ctx, "POST", apiEndpoint, body)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	repoResp := new(AnalysisResponse)
-	resp, err := s.client.Do(req, repoResp)
-	if err != nil {
-		return nil, resp, NewTenableError(resp, err)
-	}
-	return repoResp, resp, nil
+	return post[AnalysisResultSet](ctx, s.client, "/rest/analysis", body)
 }
 
 // Get wraps PostWithContext using the background context.
@@ -125,6 +109,29 @@ type AnalysisQuery struct {
 
 	Filters  []AnalysisFilter `json:"filters"`
 	VulnTool string           `json:"vulnTool"`
+
+	SortField string        `json:"sortField,omitempty"`
+	SortDir   SortDirection `json:"sortDir,omitempty"`
+	View      View          `json:"view,omitempty"`
+	ScanID    ID            `json:"scanID,omitempty"`
+}
+
+// UnmarshalJSON decodes a query whose status and times may be numbers or numeric strings, as Tenable returns them.
+func (q *AnalysisQuery) UnmarshalJSON(b []byte) error {
+	type query AnalysisQuery
+	aux := struct {
+		*query
+		Status       Int   `json:"status"`
+		CreatedTime  Epoch `json:"createdTime"`
+		ModifiedTime Epoch `json:"modifiedTime"`
+	}{query: (*query)(q), Status: Int(q.Status), CreatedTime: Epoch(q.CreatedTime), ModifiedTime: Epoch(q.ModifiedTime)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	q.Status = int64(aux.Status)
+	q.CreatedTime = int64(aux.CreatedTime)
+	q.ModifiedTime = int64(aux.ModifiedTime)
+	return nil
 }
 
 type AnalysisBody struct {
@@ -132,4 +139,65 @@ type AnalysisBody struct {
 	SourceType string        `json:"sourceType"`
 	Columns    interface{}   `json:"columns"`
 	Type       string        `json:"type"`
+
+	// SortField, SortDir, View and ScanID default to the values of Query
+	SortField string        `json:"sortField,omitempty"`
+	SortDir   SortDirection `json:"sortDir,omitempty"`
+	View      View          `json:"view,omitempty"`
+	ScanID    ID            `json:"scanID,omitempty"`
+}
+
+// AnalysisType is the kind of data an analysis query runs on.
+type AnalysisType string
+
+// Analysis types of /rest/analysis.
+const (
+	AnalysisVuln   AnalysisType = "vuln"
+	AnalysisEvent  AnalysisType = "event"
+	AnalysisMobile AnalysisType = "mobile"
+	AnalysisUser   AnalysisType = "user"
+	AnalysisSCLog  AnalysisType = "scLog"
+)
+
+// typedQuery is the query of the event, mobile, user and scLog analysis bodies.
+type typedQuery struct {
+	Type        AnalysisType     `json:"type"`
+	Tool        string           `json:"tool"`
+	SourceType  SourceType       `json:"sourceType,omitempty"`
+	StartOffset int64            `json:"startOffset"`
+	EndOffset   int64            `json:"endOffset"`
+	Filters     []AnalysisFilter `json:"filters"`
+}
+
+// typedBody is the request of the event, mobile, user and scLog analyses.
+type typedBody struct {
+	Type       AnalysisType `json:"type"`
+	SourceType SourceType   `json:"sourceType,omitempty"`
+	Query      typedQuery   `json:"query"`
+	LCEID      ID           `json:"lceID,omitempty"`
+	View       string       `json:"view,omitempty"`
+	Date       string       `json:"date,omitempty"`
+}
+
+// newTypedBody returns the request of an analysisType query. The filters are copied with their type
+// set to analysisType, filters built with NewFilter are of type vuln.
+func newTypedBody(analysisType AnalysisType, sourceType SourceType, tool string, startOffset, endOffset int64, filters []AnalysisFilter) typedBody {
+	typed := make([]AnalysisFilter, len(filters))
+	for i, f := range filters {
+		f.Type = string(analysisType)
+		typed[i] = f
+	}
+	filters = typed
+	return typedBody{
+		Type:       analysisType,
+		SourceType: sourceType,
+		Query: typedQuery{
+			Type:        analysisType,
+			Tool:        tool,
+			SourceType:  sourceType,
+			StartOffset: startOffset,
+			EndOffset:   endOffset,
+			Filters:     filters,
+		},
+	}
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package tenable
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -59,3 +60,44 @@ func TestCheckAnalysisPost200(t *testing.T) {
 		t.Fatal(fmt.Errorf("Status code should be %d", http.StatusOK))
 	}
 }
+
+func TestAnalysisQuery_UnmarshalJSON(t *testing.T) {
+	var q AnalysisQuery
+	data := `{"name":"Critical","status":"-1","createdTime":"1553525692","modifiedTime":0,"tool":"listvuln","sourceType":"cumulative"}`
+	if err := json.Unmarshal([]byte(data), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Status != -1 || q.CreatedTime != 1553525692 || q.ModifiedTime != 0 || q.Tool != "listvuln" {
+		t.Errorf("Unexpected query %+v", q)
+	}
+}
+
+func TestAnalysisService_Post_NumericQueryFields(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query map[string]json.RawMessage `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for field, want := range map[string]string{"status": "-1", "createdTime": "1553525692", "modifiedTime": "0"} {
+			if got := string(body.Query[field]); got != want {
+				t.Errorf("Encoded %s as %s, want %s", field, got, want)
+			}
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"results":[]},"error_code":0}`)
+	})
+
+	body := AnalysisBody{
+		Type:       "vuln",
+		SourceType: "cumulative",
+		Query:      AnalysisQuery{Type: "vuln", Tool: "listvuln", Status: -1, CreatedTime: 1553525692},
+	}
+	if _, _, err := testClient.Analysis.Post(body); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,11 +6,31 @@ SPDX-License-Identifier: Apache-2.0
 
 package tenable
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
 const (
 	// HTTP Basic Authentication
 	authTypeAPIKey = 1
+	// Session token / cookie based authentication
+	authTypeSession = 2
 )
 
+// sessionTokenHeader is the header carrying the token returned by /rest/token.
+// The matching TNS_SESSIONID cookie is sent along with it.
+const sessionTokenHeader = "X-SecurityCenter"
+
+// errorCodeInvalidToken is the error_code of Tenable responses to an invalid or expired session token.
+const errorCodeInvalidToken = 74
+
 // AuthenticationService handles users for the Tenable instance / API.
 //
 // Tenable API docs: https://docs.tenable.com/tenablesc/api/index.htm
@@ -24,26 +44,252 @@ type AuthenticationService struct {
 	apiKey string
 
 	apiSecret string
+
+	// session auth
+	username string
+
+	password string
+
+	// mu serializes (re-)authentication
+	mu sync.Mutex
+
+	// sessionMu guards the session stored on the client, authType and username
+	sessionMu sync.RWMutex
+}
+
+// Session represents a Session JSON response by the Tenable API.
+type Session struct {
+	Token            int64 `json:"token"`
+	ReleaseSession   bool  `json:"releaseSession"`
+	UnassociatedCert Bool  `json:"unassociatedCert,omitempty"`
+	Cookies          []*http.Cookie
 }
 
+// SessionResponse represents the response of a POST /rest/token call.
+type SessionResponse = Envelope[Session]
+
+type sessionLogin struct {
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	ReleaseSession bool   `json:"releaseSession"`
+}
+
+// reauthKey marks requests which must not trigger an automatic re-login (e.g. the login itself).
+type reauthKey struct{}
+
 // SetAPIKeyAuth sets api_key and api_secret for the APIKey auth against the Jira instance.
 //
 // Deprecated: Use APIKeyAuthTransport instead
 func (s *AuthenticationService) SetAPIKeyAuth(api_key, api_secret string) {
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
 	s.apiKey = api_key
 	s.apiSecret = api_secret
 	s.authType = authTypeAPIKey
 }
 
+// AcquireSessionCookieWithContext creates a new session for username and password against POST /rest/token.
+// The returned token and the TNS_SESSIONID cookie are stored in the client and attached to all further requests.
+// The credentials are kept in memory so that an expired session is renewed transparently.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Token.htm
+func (s *AuthenticationService) AcquireSessionCookieWithContext(ctx context.Context, username, password string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessionMu.Lock()
+	s.username = username
+	s.password = password
+	s.authType = authTypeSession
+	s.sessionMu.Unlock()
+	if err := s.login(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AcquireSessionCookie wraps AcquireSessionCookieWithContext using the background context.
+func (s *AuthenticationService) AcquireSessionCookie(username, password string) (bool, error) {
+	return s.AcquireSessionCookieWithContext(context.Background(), username, password)
+}
+
+// login performs POST /rest/token with the stored credentials. The caller must hold s.mu.
+func (s *AuthenticationService) login(ctx context.Context) error {
+	body := &sessionLogin{Username: s.username, Password: s.password}
+	req, err := s.client.NewRequestWithContext(context.WithValue(ctx, reauthKey{}, true), "POST", "rest/token", body)
+	if err != nil {
+		return err
+	}
+	// never send a stale token or cookie along with the login
+	req.Header.Del(sessionTokenHeader)
+	req.Header.Del("Cookie")
+
+	session := new(SessionResponse)
+	resp, err := s.client.Do(req, session)
+	if err != nil {
+		return fmt.Errorf("auth at Tenable instance failed (HTTP(S) request). %w", NewTenableError(resp, err))
+	}
+
+	session.Response.Cookies = resp.Cookies()
+	s.setSession(&session.Response)
+	return nil
+}
+
+// getSession returns the session stored on the client, if any.
+func (s *AuthenticationService) getSession() *Session {
+	s.sessionMu.RLock()
+	defer s.sessionMu.RUnlock()
+	return s.client.session
+}
+
+func (s *AuthenticationService) setSession(session *Session) {
+	s.sessionMu.Lock()
+	s.client.session = session
+	s.sessionMu.Unlock()
+}
+
+// authState returns the configured authentication type and the username of session auth.
+func (s *AuthenticationService) authState() (authType int, username string) {
+	s.sessionMu.RLock()
+	defer s.sessionMu.RUnlock()
+	return s.authType, s.username
+}
+
+// apiKeys returns the access and secret key of API key auth.
+func (s *AuthenticationService) apiKeys() (apiKey, apiSecret string) {
+	s.sessionMu.RLock()
+	defer s.sessionMu.RUnlock()
+	return s.apiKey, s.apiSecret
+}
+
+// LogoutWithContext releases the current session with DELETE /rest/token.
+// The stored credentials are removed, so the client will no longer re-authenticate.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Token.htm
+func (s *AuthenticationService) LogoutWithContext(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if authType, _ := s.authState(); authType != authTypeSession || s.getSession() == nil {
+		return fmt.Errorf("no user is authenticated yet")
+	}
+
+	if _, err := s.client.delete(context.WithValue(ctx, reauthKey{}, true), "rest/token"); err != nil {
+		return fmt.Errorf("error sending the logout request: %s", err)
+	}
+
+	// If logout successful, delete session
+	s.setSession(nil)
+	s.sessionMu.Lock()
+	s.username = ""
+	s.password = ""
+	s.sessionMu.Unlock()
+	return nil
+}
+
+// Logout wraps LogoutWithContext using the background context.
+func (s *AuthenticationService) Logout() error {
+	return s.LogoutWithContext(context.Background())
+}
+
 // Authenticated reports if the current Client has authentication details for Jira
 func (s *AuthenticationService) Authenticated() bool {
 	if s != nil {
 
-		if s.authType == authTypeAPIKey {
-			return (s.apiKey != "" && s.apiSecret != "")
+		authType, _ := s.authState()
+		if authType == authTypeAPIKey {
+			apiKey, apiSecret := s.apiKeys()
+			return (apiKey != "" && apiSecret != "")
+		}
+		if authType == authTypeSession {
+			return s.getSession() != nil
 		}
 		return false
 
 	}
 	return false
 }
+
+// setAuthHeaders adds the configured credentials to req.
+func (s *AuthenticationService) setAuthHeaders(req *http.Request) {
+	authType, _ := s.authState()
+	switch authType {
+	case authTypeAPIKey:
+		// Set basic auth information
+		if apiKey, apiSecret := s.apiKeys(); apiKey != "" {
+			req.Header.Set("X-Apikey", fmt.Sprintf("accesskey=%s; secretkey=%s;", apiKey, apiSecret))
+		}
+	case authTypeSession:
+		if session := s.getSession(); session != nil {
+			req.Header.Set(sessionTokenHeader, strconv.FormatInt(session.Token, 10))
+			for _, cookie := range session.Cookies {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+}
+
+// shouldReauthenticate reports whether resp signals an expired session which can be renewed
+// with the stored credentials: an HTTP 401, or an error response with the invalid token error_code.
+// Other statuses like 403 are permission errors of the logged in user and are returned as is.
+func (s *AuthenticationService) shouldReauthenticate(req *http.Request, resp *http.Response) bool {
+	if authType, username := s.authState(); authType != authTypeSession || username == "" {
+		return false
+	}
+	if skip, _ := req.Context().Value(reauthKey{}).(bool); skip {
+		return false
+	}
+	switch c := resp.StatusCode; {
+	case c == http.StatusUnauthorized:
+		return true
+	case 200 <= c && c <= 299:
+		return false
+	}
+	// read the error envelope and put it back for CheckResponse
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	var envelope struct {
+		ErrorCode int `json:"error_code"`
+	}
+	return json.Unmarshal(body, &envelope) == nil && envelope.ErrorCode == errorCodeInvalidToken
+}
+
+// needsLogin reports whether session credentials are configured but no session was acquired yet.
+func (s *AuthenticationService) needsLogin(req *http.Request) bool {
+	if authType, username := s.authState(); authType != authTypeSession || username == "" || s.getSession() != nil {
+		return false
+	}
+	skip, _ := req.Context().Value(reauthKey{}).(bool)
+	return !skip
+}
+
+// reauthenticate logs in again unless another request already renewed the session that req was sent with.
+// It returns a copy of req carrying the new credentials.
+func (s *AuthenticationService) reauthenticate(req *http.Request) (*http.Request, error) {
+	if req.Body != nil && req.GetBody == nil {
+		return nil, fmt.Errorf("session expired and request body cannot be replayed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.getSession()
+	if session == nil || req.Header.Get(sessionTokenHeader) == strconv.FormatInt(session.Token, 10) {
+		if err := s.login(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	req2 := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req2.Body = body
+	}
+	req2.Header.Del("Cookie")
+	s.setAuthHeaders(req2)
+	return req2, nil
+}
@@ -0,0 +1,251 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAuthenticationService_AcquireSessionCookie(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins := 0
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "TNS_SESSIONID", Value: fmt.Sprintf("session-%d", logins)})
+			fmt.Fprintf(w, `{"type":"regular","response":{"releaseSession":false,"token":%d},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`, 1000+logins)
+		case "DELETE":
+			testRequestURL(t, r, "/rest/token")
+			fmt.Fprint(w, `{"type":"regular","response":"","error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+		default:
+			t.Errorf("Unexpected method %s", r.Method)
+		}
+	})
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		// the first session expires after one request
+		if r.Header.Get("X-SecurityCenter") != "1002" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"type":"regular","response":"","error_code":74,"error_msg":"Invalid token","warnings":[],"timestamp":1657818772}`)
+			return
+		}
+		if c, err := r.Cookie("TNS_SESSIONID"); err != nil || c.Value != "session-2" {
+			t.Errorf("Expected session cookie session-2, got %v (%v)", c, err)
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+
+	ok, err := testClient.Authentication.AcquireSessionCookie("admin", "secret")
+	if err != nil || !ok {
+		t.Fatalf("AcquireSessionCookie returned %v, %v", ok, err)
+	}
+	if !testClient.Authentication.Authenticated() {
+		t.Fatal("Expected client to be authenticated")
+	}
+	if got := testClient.session.Token; got != 1001 {
+		t.Errorf("Session token: %d, want 1001", got)
+	}
+
+	user, _, err := testClient.CurrentUser.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "admin" {
+		t.Errorf("Username: %s, want admin", user.Username)
+	}
+	if logins != 2 {
+		t.Errorf("Expected a re-login after the session expired, got %d logins", logins)
+	}
+
+	if err := testClient.Authentication.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if testClient.Authentication.Authenticated() {
+		t.Error("Expected client to be logged out")
+	}
+}
+
+func TestAuthenticationService_AcquireSessionCookie_Failure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":1,"error_msg":"Invalid login credentials.","warnings":[],"timestamp":1657818772}`)
+	})
+
+	ok, err := testClient.Authentication.AcquireSessionCookie("admin", "wrong")
+	if err == nil || ok {
+		t.Fatal("Expected an error for invalid credentials")
+	}
+	if testClient.Authentication.Authenticated() {
+		t.Error("Expected client not to be authenticated")
+	}
+}
+
+func TestAuthenticationService_ReauthenticateFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins := 0
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		logins++
+		if logins > 1 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"type":"regular","response":"","error_code":1,"error_msg":"Invalid login credentials.","warnings":[],"timestamp":1657818772}`)
+			return
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"releaseSession":false,"token":1001},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":74,"error_msg":"Invalid token","warnings":[],"timestamp":1657818772}`)
+	})
+
+	if _, err := testClient.Authentication.AcquireSessionCookie("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := testClient.CurrentUser.Get()
+	if err == nil || !strings.Contains(err.Error(), "Invalid login credentials") {
+		t.Fatalf("Expected the re-login error, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the expired session error, got %v", err)
+	}
+}
+
+func TestAuthenticationService_ConcurrentLogin(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"regular","response":{"releaseSession":false,"token":1001},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+
+	// run with -race: requests read the credentials while they are replaced
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := testClient.Authentication.AcquireSessionCookie("admin", "secret"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			testClient.CurrentUser.Get()
+		}()
+	}
+	wg.Wait()
+	if !testClient.Authentication.Authenticated() {
+		t.Error("Expected client to be authenticated")
+	}
+}
+
+func TestAuthenticationService_ConcurrentAPIKeyRotation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("X-Apikey"), "accesskey=key") {
+			t.Errorf("Unexpected X-Apikey header %q", r.Header.Get("X-Apikey"))
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+
+	// run with -race: requests read the keys while they are rotated
+	testClient.Authentication.SetAPIKeyAuth("key0", "secret0")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				testClient.Authentication.SetAPIKeyAuth(fmt.Sprintf("key%d", i), fmt.Sprintf("secret%d", i))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			testClient.CurrentUser.Get()
+			for j := 0; j < 100; j++ {
+				testClient.Authentication.Authenticated()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAuthenticationService_ForbiddenIsNotReauthenticated(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins, calls := 0, 0
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		fmt.Fprint(w, `{"type":"regular","response":{"releaseSession":false,"token":1001},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":146,"error_msg":"Permission denied.","warnings":[],"timestamp":1657818772}`)
+	})
+
+	if _, err := testClient.Authentication.AcquireSessionCookie("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := testClient.CurrentUser.Get()
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected the 403 error, got %v", err)
+	}
+	if logins != 1 || calls != 1 {
+		t.Errorf("Got %d logins and %d requests, want 1 and 1", logins, calls)
+	}
+}
+
+func TestAuthenticationService_InvalidTokenCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins, calls := 0, 0
+	testMux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		fmt.Fprint(w, `{"type":"regular","response":{"releaseSession":false,"token":1001},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"type":"regular","response":"","error_code":74,"error_msg":"Invalid token","warnings":[],"timestamp":1657818772}`)
+			return
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0,"error_msg":"","warnings":[],"timestamp":1657818772}`)
+	})
+
+	if _, err := testClient.Authentication.AcquireSessionCookie("admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := testClient.CurrentUser.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 2 || calls != 2 {
+		t.Errorf("Got %d logins and %d requests, want 2 and 2", logins, calls)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SourceType is the data source of an analysis query.
+type SourceType string
+
+// Source types of vulnerability analysis.
+const (
+	// SourceCumulative queries the current vulnerabilities of the repositories
+	SourceCumulative SourceType = "cumulative"
+	// SourcePatched queries mitigated vulnerabilities
+	SourcePatched SourceType = "patched"
+	// SourceIndividual queries the results of a single scan, ScanID is required
+	SourceIndividual SourceType = "individual"
+)
+
+// SortDirection is the direction of server-side sorting.
+type SortDirection string
+
+// Sort directions.
+const (
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+// View selects the findings of an individual scan result.
+type View string
+
+// Views of individual scan results.
+const (
+	ViewAll     View = "all"
+	ViewNew     View = "new"
+	ViewPatched View = "patched"
+)
+
+// Column is a column of the analysis results. Only the requested columns are returned.
+type Column struct {
+	Name string `json:"name"`
+}
+
+// Columns returns the columns of the given names.
+func Columns(names ...string) []Column {
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		columns[i] = Column{Name: name}
+	}
+	return columns
+}
+
+// WithSourceType returns a copy of b querying the source type t.
+func (b AnalysisBody) WithSourceType(t SourceType) AnalysisBody {
+	b.SourceType = string(t)
+	b.Query.SourceType = string(t)
+	return b
+}
+
+// WithColumns returns a copy of b requesting only the columns of the given names.
+func (b AnalysisBody) WithColumns(names ...string) AnalysisBody {
+	b.Columns = Columns(names...)
+	return b
+}
+
+// columnList returns the columns of b, which may be set as []Column or any value encoding the same way.
+func (b AnalysisBody) columnList() ([]Column, error) {
+	switch c := b.Columns.(type) {
+	case nil:
+		return nil, nil
+	case []Column:
+		return c, nil
+	}
+	raw, err := json.Marshal(b.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("tenable: invalid columns: %w", err)
+	}
+	var columns []Column
+	if err := json.Unmarshal(raw, &columns); err != nil {
+		return nil, fmt.Errorf("tenable: invalid columns %s, want a list of {\"name\": ...} objects", raw)
+	}
+	return columns, nil
+}
+
+var (
+	toolFieldsOnce sync.Once
+	toolFields     map[Tool]map[string]bool
+)
+
+// ToolFields returns the sortable and selectable fields of the results of tool, sorted.
+func ToolFields(tool Tool) []string {
+	fields := make([]string, 0, len(toolFieldSet(tool)))
+	for name := range toolFieldSet(tool) {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// toolFieldSet returns the JSON field names of the result type of tool.
+func toolFieldSet(tool Tool) map[string]bool {
+	toolFieldsOnce.Do(func() {
+		toolFields = make(map[Tool]map[string]bool, len(vulnTools))
+		for t, result := range vulnTools {
+			fields := map[string]bool{}
+			collectJSONFields(reflect.TypeOf(result), fields)
+			toolFields[t] = fields
+		}
+	})
+	return toolFields[tool]
+}
+
+func collectJSONFields(t reflect.Type, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" {
+			collectJSONFields(f.Type, fields)
+			continue
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+}
+
+// Validate checks the request options of b against its source type and tool,
+// the same way Query, StreamQuery and the other typed queries do before sending it.
+// AnalysisService.Post and Stream send their bodies as is.
+func (b AnalysisBody) Validate() error {
+	_, err := b.normalize()
+	return err
+}
+
+// merge returns the value set on the body or the query, it fails if both differ.
+func merge[T comparable](name string, body, query T) (T, error) {
+	var zero T
+	if body != zero && query != zero && body != query {
+		return zero, fmt.Errorf("tenable: %s %v of the body does not match %v of the query", name, body, query)
+	}
+	if body == zero {
+		return query, nil
+	}
+	return body, nil
+}
+
+// normalize validates the request options of b and returns it in the shape of its source type:
+// type and source type are set on both body and query, cumulative and patched queries carry
+// the sort options, individual queries additionally carry the scan ID and view on the body.
+func (b AnalysisBody) normalize() (AnalysisBody, error) {
+	var err error
+	if b.Type, err = merge("type", b.Type, b.Query.Type); err != nil {
+		return b, err
+	}
+	if b.Type == "" {
+		b.Type = "vuln"
+	}
+	b.Query.Type = b.Type
+	if b.SourceType, err = merge("sourceType", b.SourceType, b.Query.SourceType); err != nil {
+		return b, err
+	}
+	if b.SourceType == "" && b.Type == "vuln" {
+		b.SourceType = string(SourceCumulative)
+	}
+	b.Query.SourceType = b.SourceType
+
+	if b.SortField, err = merge("sortField", b.SortField, b.Query.SortField); err != nil {
+		return b, err
+	}
+	if b.SortDir, err = merge("sortDir", SortDirection(strings.ToUpper(string(b.SortDir))), SortDirection(strings.ToUpper(string(b.Query.SortDir)))); err != nil {
+		return b, err
+	}
+	if b.View, err = merge("view", b.View, b.Query.View); err != nil {
+		return b, err
+	}
+	if b.ScanID, err = merge("scanID", b.ScanID, b.Query.ScanID); err != nil {
+		return b, err
+	}
+
+	switch b.SortDir {
+	case "", SortAsc, SortDesc:
+	default:
+		return b, fmt.Errorf("tenable: invalid sortDir %q, use ASC or DESC", b.SortDir)
+	}
+	if b.SortDir != "" && b.SortField == "" {
+		return b, fmt.Errorf("tenable: sortDir %s without sortField", b.SortDir)
+	}
+	b.Query.SortField, b.Query.SortDir = b.SortField, b.SortDir
+
+	switch SourceType(b.SourceType) {
+	case SourceCumulative, SourcePatched:
+		if b.ScanID != 0 || b.View != "" {
+			return b, fmt.Errorf("tenable: scanID and view require the %s source type, not %s", SourceIndividual, b.SourceType)
+		}
+	case SourceIndividual:
+		if b.ScanID == 0 {
+			return b, fmt.Errorf("tenable: the %s source type requires a scanID", SourceIndividual)
+		}
+		switch b.View {
+		case "":
+			b.View = ViewAll
+		case ViewAll, ViewNew, ViewPatched:
+		default:
+			return b, fmt.Errorf("tenable: invalid view %q, use all, new or patched", b.View)
+		}
+		b.Query.ScanID, b.Query.View = b.ScanID, b.View
+	default:
+		if b.Type == "vuln" {
+			return b, fmt.Errorf("tenable: invalid sourceType %q", b.SourceType)
+		}
+	}
+
+	if b.Type != "vuln" {
+		return b, nil
+	}
+	tool := Tool(b.Query.Tool)
+	if tool == "" {
+		tool = Tool(b.Query.VulnTool)
+	}
+	columns, err := b.columnList()
+	if err != nil {
+		return b, err
+	}
+	if b.SortField == "" && len(columns) == 0 {
+		// tools unknown to this package are passed through as long as no options depend on them
+		return b, nil
+	}
+	if tool == "" {
+		tool = ToolListVuln
+		b.Query.Tool = string(tool)
+	}
+	if !tool.Valid() {
+		return b, fmt.Errorf("tenable: unknown analysis tool %q", tool)
+	}
+	fields := toolFieldSet(tool)
+	if b.SortField != "" && !fields[b.SortField] {
+		return b, fmt.Errorf("tenable: cannot sort %s results by %q", tool, b.SortField)
+	}
+	for _, c := range columns {
+		if !fields[c.Name] {
+			return b, fmt.Errorf("tenable: %s results have no column %q", tool, c.Name)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAnalysisBody_Individual(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := body["query"].(map[string]interface{})
+		if body["sourceType"] != "individual" || query["sourceType"] != "individual" || body["type"] != "vuln" {
+			t.Errorf("Unexpected source type or type in %v", body)
+		}
+		if body["scanID"] != "42" || body["view"] != "all" || body["sortField"] != "vprScore" || body["sortDir"] != "DESC" {
+			t.Errorf("Unexpected request options in %v", body)
+		}
+		if query["sortField"] != "vprScore" || query["scanID"] != "42" {
+			t.Errorf("Unexpected query options in %v", query)
+		}
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"0","results":[]},"error_code":0}`))
+	})
+
+	body := AnalysisBody{
+		ScanID: 42,
+		Query:  AnalysisQuery{Tool: "listvuln", SortField: "vprScore", SortDir: "desc"},
+	}.WithSourceType(SourceIndividual).WithColumns("pluginID", "ip", "vprScore")
+	if _, _, err := Query[Analysis](context.Background(), testClient.Analysis, body); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalysisService_Post_Raw(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := body["query"].(map[string]interface{})
+		if body["type"] != "" || body["sourceType"] != "" || query["type"] != "event" || query["sourceType"] != "" {
+			t.Errorf("Expected the body as is, got %v", body)
+		}
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"0","results":[]},"error_code":0}`))
+	})
+
+	body := AnalysisBody{Query: AnalysisQuery{Type: "event", Tool: "listvuln"}}
+	if _, _, err := testClient.Analysis.Post(body); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalysisBody_Validate(t *testing.T) {
+	tests := []struct {
+		body AnalysisBody
+		err  string
+	}{
+		{AnalysisBody{}, ""},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumip", SortField: "score", SortDir: SortDesc}}, ""},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumip"}, Columns: Columns("severityCritical", "dnsName")}, ""},
+		{AnalysisBody{SortField: "vprScore"}, ""},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumport", SortField: "vprScore"}}, "cannot sort sumport results"},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumid"}, Columns: Columns("bogus")}, "no column"},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumip"}, Columns: []map[string]string{{"name": "score"}}}, ""},
+		{AnalysisBody{Query: AnalysisQuery{Tool: "sumip"}, Columns: []string{"score"}}, "invalid columns"},
+		{AnalysisBody{SortDir: SortAsc}, "without sortField"},
+		{AnalysisBody{SortField: "ip", SortDir: "up"}, "invalid sortDir"},
+		{AnalysisBody{ScanID: 3}, "require the individual source type"},
+		{AnalysisBody{View: ViewNew}.WithSourceType(SourcePatched), "require the individual source type"},
+		{AnalysisBody{SourceType: "individual"}, "requires a scanID"},
+		{AnalysisBody{ScanID: 3, View: "old"}.WithSourceType(SourceIndividual), "invalid view"},
+		{AnalysisBody{SourceType: "archive"}, "invalid sourceType"},
+		{AnalysisBody{SourceType: "patched", Query: AnalysisQuery{SourceType: "cumulative"}}, "does not match"},
+		{AnalysisBody{SortField: "ip", Query: AnalysisQuery{Tool: "mystery"}}, "unknown analysis tool"},
+	}
+	for _, tt := range tests {
+		err := tt.body.Validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%+v: got error %v, want %q", tt.body, err, tt.err)
+		}
+	}
+}
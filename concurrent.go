@@ -0,0 +1,182 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultFetchWorkers is the number of pages fetched in parallel if no worker count is given.
+const DefaultFetchWorkers = 4
+
+// FetchOptions configures the concurrent fetching of analysis results.
+type FetchOptions struct {
+	// PageSize is the number of records per request, DefaultPageSize if not positive
+	PageSize int
+	// Workers is the number of parallel requests, DefaultFetchWorkers if not positive.
+	// Every request still waits for the rate limit of the client.
+	Workers int
+}
+
+func (o *FetchOptions) pageSize() int {
+	if o == nil || o.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return o.PageSize
+}
+
+func (o *FetchOptions) workers() int {
+	if o == nil || o.Workers <= 0 {
+		return DefaultFetchWorkers
+	}
+	return o.Workers
+}
+
+// OffsetRange is the window [Start, End) of an analysis query.
+type OffsetRange struct {
+	Start int64
+	End   int64
+}
+
+func (r OffsetRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// RangeError is the failure of fetching a single offset range.
+type RangeError struct {
+	Range OffsetRange
+	Err   error
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("offsets %s: %v", e.Range, e.Err)
+}
+
+func (e *RangeError) Unwrap() error {
+	return e.Err
+}
+
+// PartialFetchError is returned if some offset ranges of a concurrent fetch failed.
+// The results of all other ranges are returned along with it.
+type PartialFetchError struct {
+	Failed []*RangeError
+}
+
+func (e *PartialFetchError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("tenable: %d offset ranges failed: %s", len(e.Failed), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed ranges, so errors.Is and errors.As see them.
+func (e *PartialFetchError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Ranges returns the failed offset ranges, ready to be passed to FetchRangesWithContext.
+func (e *PartialFetchError) Ranges() []OffsetRange {
+	ranges := make([]OffsetRange, len(e.Failed))
+	for i, f := range e.Failed {
+		ranges[i] = f.Range
+	}
+	return ranges
+}
+
+// FetchAllWithContext fetches every result of the analysis query in body, starting at body.Query.StartOffset.
+// The first page is fetched to learn totalRecords and the page size the server allows, the remaining
+// offset windows of that size are then fetched in parallel by a bounded pool of workers. Results are returned in offset order.
+// If some windows fail, the results of all other windows are returned together with a *PartialFetchError
+// listing the failed ranges, which can be retried with FetchRangesWithContext.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) FetchAllWithContext(ctx context.Context, body AnalysisBody, opts *FetchOptions) ([]Analysis, error) {
+	pageSize := int64(opts.pageSize())
+	first := OffsetRange{Start: body.Query.StartOffset, End: body.Query.StartOffset + pageSize}
+	page, err := s.fetchRange(ctx, body, first)
+	if err != nil {
+		return nil, err
+	}
+
+	// the server may cap the page size, so the windows are as large as the first page really was
+	var ranges []OffsetRange
+	total := int64(page.Response.TotalRecords)
+	step := int64(len(page.Response.Results))
+	for start := first.Start + step; start < total && step > 0; start += step {
+		ranges = append(ranges, OffsetRange{Start: start, End: start + step})
+	}
+	rest, err := s.FetchRangesWithContext(ctx, body, ranges, opts)
+	return append(page.Response.Results, rest...), err
+}
+
+// FetchRangesWithContext fetches the given offset ranges of the analysis query in body in parallel
+// and returns their results in the order of ranges. Failed ranges are reported in a *PartialFetchError.
+// Once ctx is done, no further ranges are requested and the remaining ranges are reported as failed.
+func (s *AnalysisService) FetchRangesWithContext(ctx context.Context, body AnalysisBody, ranges []OffsetRange, opts *FetchOptions) ([]Analysis, error) {
+	pages := make([][]Analysis, len(ranges))
+	failed := make([]error, len(ranges))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.workers() && w < len(ranges); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					failed[i] = err
+					continue
+				}
+				page, err := s.fetchRange(ctx, body, ranges[i])
+				if err != nil {
+					failed[i] = err
+					continue
+				}
+				pages[i] = page.Response.Results
+			}
+		}()
+	}
+	for i := range ranges {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var results []Analysis
+	partial := &PartialFetchError{}
+	for i, page := range pages {
+		if failed[i] != nil {
+			partial.Failed = append(partial.Failed, &RangeError{Range: ranges[i], Err: failed[i]})
+			continue
+		}
+		results = append(results, page...)
+	}
+	if len(partial.Failed) > 0 {
+		return results, partial
+	}
+	return results, nil
+}
+
+// fetchRange fetches a single offset window of the analysis query in body.
+func (s *AnalysisService) fetchRange(ctx context.Context, body AnalysisBody, r OffsetRange) (*AnalysisResponse, error) {
+	if r.End <= r.Start {
+		return nil, errors.New("tenable: empty offset range")
+	}
+	body.Query.StartOffset = r.Start
+	body.Query.EndOffset = r.End
+	page, _, err := s.PostWithContext(ctx, body)
+	return page, err
+}
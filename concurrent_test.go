@@ -0,0 +1,140 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestAnalysisService_FetchAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	failOnce := map[int64]bool{40: true}
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body AnalysisBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			return
+		}
+		start, end := body.Query.StartOffset, body.Query.EndOffset
+		mu.Lock()
+		fail := failOnce[start]
+		delete(failOnce, start)
+		mu.Unlock()
+		if fail {
+			w.Write([]byte(`{"type":"regular","response":"","error_code":130,"error_msg":"Query timed out."}`))
+			return
+		}
+		if end > 95 {
+			end = 95
+		}
+		rs := AnalysisResultSet{TotalRecords: 95}
+		for i := start; i < end; i++ {
+			rs.Results = append(rs.Results, Analysis{PluginID: ID(i)})
+		}
+		json.NewEncoder(w).Encode(AnalysisResponse{Type: "regular", Response: rs})
+	})
+
+	opts := &FetchOptions{PageSize: 10, Workers: 3}
+	results, err := testClient.Analysis.FetchAllWithContext(context.Background(), AnalysisBody{Type: "vuln"}, opts)
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected a *PartialFetchError, got %v", err)
+	}
+	if ranges := partial.Ranges(); len(ranges) != 1 || ranges[0] != (OffsetRange{Start: 40, End: 50}) {
+		t.Fatalf("Unexpected failed ranges %v", ranges)
+	}
+	if !errors.Is(err, &APIError{Code: 130}) {
+		t.Errorf("Expected the cause of the failed range, got %v", err)
+	}
+	if len(results) != 85 {
+		t.Fatalf("Got %d results, want 85", len(results))
+	}
+	for i, r := range results {
+		want := ID(i)
+		if i >= 40 {
+			want += 10
+		}
+		if r.PluginID != want {
+			t.Fatalf("Result %d: PluginID %d, want %d", i, r.PluginID, want)
+		}
+	}
+
+	retried, err := testClient.Analysis.FetchRangesWithContext(context.Background(), AnalysisBody{Type: "vuln"}, partial.Ranges(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retried) != 10 || retried[0].PluginID != 40 {
+		t.Errorf("Unexpected retried results %+v", retried)
+	}
+}
+
+func TestAnalysisService_FetchRangesCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request after cancellation")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ranges := []OffsetRange{{0, 10}, {10, 20}}
+	results, err := testClient.Analysis.FetchRangesWithContext(ctx, AnalysisBody{}, ranges, nil)
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Failed) != 2 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected both ranges to fail with context.Canceled, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestAnalysisService_FetchAllCappedPageSize(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body AnalysisBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			return
+		}
+		// the server returns at most 7 records per request
+		start, end := body.Query.StartOffset, body.Query.EndOffset
+		if end > start+7 {
+			end = start + 7
+		}
+		if end > 30 {
+			end = 30
+		}
+		rs := AnalysisResultSet{TotalRecords: 30}
+		for i := start; i < end; i++ {
+			rs.Results = append(rs.Results, Analysis{PluginID: ID(i)})
+		}
+		json.NewEncoder(w).Encode(AnalysisResponse{Type: "regular", Response: rs})
+	})
+
+	results, err := testClient.Analysis.FetchAllWithContext(context.Background(), AnalysisBody{Type: "vuln"}, &FetchOptions{PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 30 {
+		t.Fatalf("Got %d results, want 30", len(results))
+	}
+	for i, r := range results {
+		if r.PluginID != ID(i) {
+			t.Fatalf("Result %d: PluginID %d, want %d", i, r.PluginID, i)
+		}
+	}
+}
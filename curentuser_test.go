@@ -57,4 +57,7 @@ func TestCheckCurrentUserGet200(t *testing.T) {
 	if resp.StatusCode != http.StatusOK {
 		t.Fatal(fmt.Errorf("Status code should be %d", http.StatusOK))
 	}
+	if user.ID != 1 || user.Locked || user.LastLogin.Time().Unix() != 1454350174 {
+		t.Errorf("Unexpected user %+v", user.User)
+	}
 }
@@ -8,7 +8,6 @@ package tenable
 
 import (
 	"context"
-	"fmt"
 )
 
 // CurrentUserService handles users for the Tenable instance / API.
@@ -18,42 +17,21 @@ type CurrentUserService struct {
 	client *Client
 }
 
-// CurrentUserResponse represents a Tenable user response
+// CurrentUser represents the Tenable user owning the credentials of the client.
 type CurrentUser struct {
 	User
 }
 
-// CurrentUser represents a Tenable user.
-type CurrentUserResponse struct {
-	// "type": "regular",
-	Type string
-	// "response": {
-	Response CurrentUser `json:"response"`
-	// "error_code": 0,
-	ErrorCode int `json:"error_code"`
-	// "error_msg": "",
-	ErrorMsg string `json:"error_msg"`
-	// "warnings": [],
-	Warnings []string
-	// "timestamp": 1657818772
-	Timestamp int
-}
+// CurrentUserResponse represents a Tenable user response
+type CurrentUserResponse = Envelope[CurrentUser]
 
 // GetWithContext gets user info from Tenable using its Account Id
 //
 // Tenable API docs: https://docs.tenable.com/tenablesc/api/CurrentUser.md
 func (s *CurrentUserService) GetWithContext(ctx context.Context) (*CurrentUser, *Response, error) {
-	apiEndpoint := fmt.Sprintf("/rest/currentUser")
-	req, err := s.client.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	user := new(CurrentUserResponse)
-	//fmt.Println(req.URL)
-	resp, err := s.client.Do(req, user)
+	user, resp, err := get[CurrentUser](ctx, s.client, "/rest/currentUser")
 	if err != nil {
-		return nil, resp, NewTenableError(resp, err)
+		return nil, resp, err
 	}
 	return &user.Response, resp, nil
 }
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cvss parses CVSS v2, v3.x and v4.0 vectors and computes their scores
+// following the specifications of FIRST (https://www.first.org/cvss/).
+package cvss
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidVector is wrapped by all errors about malformed vectors and metric values.
+var ErrInvalidVector = errors.New("cvss: invalid vector")
+
+// Vector is a parsed CVSS vector. Metrics are identified by the abbreviations of the specification, e.g. "AV".
+type Vector interface {
+	// Version returns the CVSS version, "2.0", "3.0", "3.1" or "4.0"
+	Version() string
+	// Get returns the value of a metric, the "not defined" value (X or ND) for unset optional metrics
+	// or an empty string for metrics the version does not define
+	Get(metric string) string
+	// Set sets the value of a metric, setting X or ND unsets it
+	Set(metric, value string) error
+	// Defines reports whether the version has the metric
+	Defines(metric string) bool
+	// BaseScore returns the base score, CVSS-B for v4.0
+	BaseScore() float64
+	// TemporalScore returns the temporal score, CVSS-BT (the threat score) for v4.0
+	TemporalScore() float64
+	// EnvironmentalScore returns the score of all metrics, CVSS-BTE for v4.0
+	EnvironmentalScore() float64
+	// String returns the vector in its canonical form, without unset metrics
+	String() string
+
+	clone() Vector
+}
+
+// Parse parses a vector of any version: "CVSS:4.0/..." and "CVSS:3.x/..." vectors by their prefix,
+// vectors without prefix as v2 if they have the Au metric and as v3.1 otherwise.
+func Parse(s string) (Vector, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "CVSS:4"):
+		return ParseV4(s)
+	case strings.HasPrefix(s, "CVSS:3"):
+		return ParseV3(s)
+	case strings.HasPrefix(s, "CVSS2#"), strings.Contains(s, "Au:"):
+		return ParseV2(s)
+	}
+	return ParseV3(s)
+}
+
+// Rating returns the qualitative severity rating of a v3.x or v4.0 score: None, Low, Medium, High or Critical.
+func Rating(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Medium"
+	case score > 0:
+		return "Low"
+	}
+	return "None"
+}
+
+// Environment are environmental metric overrides, e.g. the security requirements of an asset,
+// keyed by metric abbreviation: {"CR": "H", "MAV": "L"}.
+// Metrics which the version of a vector does not define are ignored, so an Environment can hold the
+// overrides of several versions.
+type Environment map[string]string
+
+// Apply returns a copy of v with the metrics of e set.
+func (e Environment) Apply(v Vector) (Vector, error) {
+	c := v.clone()
+	for metric, value := range e {
+		if !c.Defines(metric) {
+			continue
+		}
+		if err := c.Set(metric, value); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// metricDef is a metric of a CVSS version. The first of the values of an optional metric means not defined.
+type metricDef struct {
+	name     string
+	values   []string
+	optional bool
+}
+
+// metrics are the metric values of a vector.
+type metrics struct {
+	prefix string
+	defs   []metricDef
+	values map[string]string
+}
+
+func (m *metrics) def(name string) (metricDef, bool) {
+	for _, d := range m.defs {
+		if d.name == name {
+			return d, true
+		}
+	}
+	return metricDef{}, false
+}
+
+// Defines reports whether the version has the metric.
+func (m *metrics) Defines(metric string) bool {
+	_, ok := m.def(metric)
+	return ok
+}
+
+// Get returns the value of a metric, see Vector.
+func (m *metrics) Get(metric string) string {
+	if v, ok := m.values[metric]; ok {
+		return v
+	}
+	if d, ok := m.def(metric); ok && d.optional {
+		return d.values[0]
+	}
+	return ""
+}
+
+// Set sets the value of a metric, see Vector.
+func (m *metrics) Set(metric, value string) error {
+	d, ok := m.def(metric)
+	if !ok {
+		return fmt.Errorf("%w: unknown metric %s", ErrInvalidVector, metric)
+	}
+	if !contains(d.values, value) {
+		return fmt.Errorf("%w: invalid value %s:%s", ErrInvalidVector, metric, value)
+	}
+	if d.optional && value == d.values[0] {
+		delete(m.values, metric)
+		return nil
+	}
+	m.values[metric] = value
+	return nil
+}
+
+// String returns the vector in its canonical form.
+func (m *metrics) String() string {
+	parts := make([]string, 0, len(m.values)+1)
+	if m.prefix != "" {
+		parts = append(parts, m.prefix)
+	}
+	for _, d := range m.defs {
+		if v, ok := m.values[d.name]; ok {
+			parts = append(parts, d.name+":"+v)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (m *metrics) copy() metrics {
+	c := *m
+	c.values = make(map[string]string, len(m.values))
+	for k, v := range m.values {
+		c.values[k] = v
+	}
+	return c
+}
+
+// parseMetrics parses the "metric:value" parts of s separated by slashes, s without its version prefix.
+func parseMetrics(s, prefix string, defs []metricDef) (metrics, error) {
+	m := metrics{prefix: prefix, defs: defs, values: make(map[string]string)}
+	if s == "" {
+		return m, fmt.Errorf("%w: empty vector", ErrInvalidVector)
+	}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return m, fmt.Errorf("%w: malformed metric %q", ErrInvalidVector, part)
+		}
+		if seen[name] {
+			return m, fmt.Errorf("%w: duplicate metric %s", ErrInvalidVector, name)
+		}
+		seen[name] = true
+		if err := m.Set(name, value); err != nil {
+			return m, err
+		}
+	}
+	for _, d := range defs {
+		if _, ok := m.values[d.name]; !ok && !d.optional {
+			return m, fmt.Errorf("%w: missing base metric %s", ErrInvalidVector, d.name)
+		}
+	}
+	return m, nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
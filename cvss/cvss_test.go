@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import (
+	"errors"
+	"testing"
+)
+
+// scoreTest is a vector and its base, temporal and environmental scores.
+type scoreTest struct {
+	vector                        string
+	base, temporal, environmental float64
+}
+
+func testScores(t *testing.T, tests []scoreTest) {
+	t.Helper()
+	for _, test := range tests {
+		v, err := Parse(test.vector)
+		if err != nil {
+			t.Errorf("%s: %v", test.vector, err)
+			continue
+		}
+		if b, tm, e := v.BaseScore(), v.TemporalScore(), v.EnvironmentalScore(); b != test.base || tm != test.temporal || e != test.environmental {
+			t.Errorf("%s: scores %v %v %v, want %v %v %v", test.vector, b, tm, e, test.base, test.temporal, test.environmental)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		vector, version, canonical string
+	}{
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:X", "4.0", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "3.0", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+		{"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/RC:C/E:U", "3.1", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U/RC:C"},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P/E:ND", "2.0", "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		{"CVSS2#AV:N/AC:L/Au:N/C:P/I:P/A:P", "2.0", "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		{"(AV:L/AC:H/Au:S/C:C/I:N/A:N)", "2.0", "AV:L/AC:H/Au:S/C:C/I:N/A:N"},
+	}
+	for _, test := range tests {
+		v, err := Parse(test.vector)
+		if err != nil {
+			t.Errorf("%s: %v", test.vector, err)
+			continue
+		}
+		if v.Version() != test.version || v.String() != test.canonical {
+			t.Errorf("%s: version %s %s, want %s %s", test.vector, v.Version(), v, test.version, test.canonical)
+		}
+	}
+
+	for _, invalid := range []string{
+		"",
+		"CVSS:3.2/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",         // missing A
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/A:L", // duplicate
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:S/SA:N", // S only for MSI
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P/XX:Y",
+		"AV:N/AC:L/Au:N/C:P/I:P/A",
+	} {
+		if _, err := Parse(invalid); !errors.Is(err, ErrInvalidVector) {
+			t.Errorf("%q: expected ErrInvalidVector, got %v", invalid, err)
+		}
+	}
+}
+
+func TestEnvironment_Apply(t *testing.T) {
+	v, err := Parse("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an isolated asset, v2 and v4 only metrics are ignored
+	internal := Environment{"MAV": "L", "CDP": "L", "MSI": "S"}
+	scored, err := internal.Apply(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scored.EnvironmentalScore() != 8.4 || scored.Get("MAV") != "L" {
+		t.Errorf("Unexpected environmental score %v of %s", scored.EnvironmentalScore(), scored)
+	}
+	if v.Get("MAV") != "X" || v.EnvironmentalScore() != 9.8 {
+		t.Errorf("Apply changed the original vector %s", v)
+	}
+	if _, err := (Environment{"CR": "Z"}).Apply(v); !errors.Is(err, ErrInvalidVector) {
+		t.Errorf("Expected an invalid value error, got %v", err)
+	}
+}
+
+func TestRating(t *testing.T) {
+	for score, want := range map[float64]string{0: "None", 0.1: "Low", 3.9: "Low", 4: "Medium", 6.9: "Medium", 7: "High", 8.9: "High", 9: "Critical", 10: "Critical"} {
+		if got := Rating(score); got != want {
+			t.Errorf("Rating(%v) = %s, want %s", score, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import (
+	"math"
+	"strings"
+)
+
+var v2Metrics = []metricDef{
+	{name: "AV", values: []string{"L", "A", "N"}},
+	{name: "AC", values: []string{"H", "M", "L"}},
+	{name: "Au", values: []string{"M", "S", "N"}},
+	{name: "C", values: []string{"N", "P", "C"}},
+	{name: "I", values: []string{"N", "P", "C"}},
+	{name: "A", values: []string{"N", "P", "C"}},
+	{name: "E", values: []string{"ND", "U", "POC", "F", "H"}, optional: true},
+	{name: "RL", values: []string{"ND", "OF", "TF", "W", "U"}, optional: true},
+	{name: "RC", values: []string{"ND", "UC", "UR", "C"}, optional: true},
+	{name: "CDP", values: []string{"ND", "N", "L", "LM", "MH", "H"}, optional: true},
+	{name: "TD", values: []string{"ND", "N", "L", "M", "H"}, optional: true},
+	{name: "CR", values: []string{"ND", "L", "M", "H"}, optional: true},
+	{name: "IR", values: []string{"ND", "L", "M", "H"}, optional: true},
+	{name: "AR", values: []string{"ND", "L", "M", "H"}, optional: true},
+}
+
+var v2Weights = map[string]map[string]float64{
+	"AV":  {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC":  {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au":  {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":   {"N": 0, "P": 0.275, "C": 0.660},
+	"I":   {"N": 0, "P": 0.275, "C": 0.660},
+	"A":   {"N": 0, "P": 0.275, "C": 0.660},
+	"E":   {"ND": 1, "U": 0.85, "POC": 0.9, "F": 0.95, "H": 1},
+	"RL":  {"ND": 1, "OF": 0.87, "TF": 0.9, "W": 0.95, "U": 1},
+	"RC":  {"ND": 1, "UC": 0.9, "UR": 0.95, "C": 1},
+	"CDP": {"ND": 0, "N": 0, "L": 0.1, "LM": 0.3, "MH": 0.4, "H": 0.5},
+	"TD":  {"ND": 1, "N": 0, "L": 0.25, "M": 0.75, "H": 1},
+	"CR":  {"ND": 1, "L": 0.5, "M": 1, "H": 1.51},
+	"IR":  {"ND": 1, "L": 0.5, "M": 1, "H": 1.51},
+	"AR":  {"ND": 1, "L": 0.5, "M": 1, "H": 1.51},
+}
+
+// V2 is a CVSS v2 vector, e.g. "AV:N/AC:L/Au:N/C:P/I:P/A:P". Unset optional metrics are "ND".
+type V2 struct {
+	metrics
+}
+
+// ParseV2 parses a CVSS v2 vector, optionally enclosed in parentheses or prefixed by "CVSS2#".
+func ParseV2(s string) (*V2, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "CVSS2#")
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	m, err := parseMetrics(s, "", v2Metrics)
+	if err != nil {
+		return nil, err
+	}
+	return &V2{m}, nil
+}
+
+// Version returns "2.0".
+func (v *V2) Version() string { return "2.0" }
+
+func (v *V2) clone() Vector { return &V2{v.copy()} }
+
+func (v *V2) weight(metric string) float64 {
+	return v2Weights[metric][v.Get(metric)]
+}
+
+// BaseScore returns the base score.
+func (v *V2) BaseScore() float64 {
+	impact := 10.41 * (1 - (1-v.weight("C"))*(1-v.weight("I"))*(1-v.weight("A")))
+	return v.base(impact)
+}
+
+func (v *V2) base(impact float64) float64 {
+	exploitability := 20 * v.weight("AV") * v.weight("AC") * v.weight("Au")
+	f := 1.176
+	if impact == 0 {
+		f = 0
+	}
+	return round1((0.6*impact + 0.4*exploitability - 1.5) * f)
+}
+
+func (v *V2) temporal(base float64) float64 {
+	return round1(base * v.weight("E") * v.weight("RL") * v.weight("RC"))
+}
+
+// TemporalScore returns the temporal score, which is the base score if no temporal metric is set.
+func (v *V2) TemporalScore() float64 {
+	return v.temporal(v.BaseScore())
+}
+
+// EnvironmentalScore returns the environmental score, which is the temporal score if no environmental metric is set.
+func (v *V2) EnvironmentalScore() float64 {
+	impact := math.Min(10, 10.41*(1-
+		(1-v.weight("C")*v.weight("CR"))*
+			(1-v.weight("I")*v.weight("IR"))*
+			(1-v.weight("A")*v.weight("AR"))))
+	adjusted := v.temporal(v.base(impact))
+	return round1((adjusted + (10-adjusted)*v.weight("CDP")) * v.weight("TD"))
+}
+
+// round1 rounds to one decimal.
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
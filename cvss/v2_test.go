@@ -0,0 +1,21 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import "testing"
+
+func TestV2_Scores(t *testing.T) {
+	// the examples of the CVSS v2 specification, section 3.3
+	testScores(t, []scoreTest{
+		{"AV:N/AC:L/Au:N/C:N/I:N/A:C/E:F/RL:OF/RC:C/CDP:H/TD:H/CR:M/IR:M/AR:H", 7.8, 6.4, 9.2},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C/E:F/RL:OF/RC:C/CDP:H/TD:H/CR:M/IR:M/AR:L", 10, 8.3, 9.0},
+		{"AV:L/AC:H/Au:N/C:C/I:C/A:C/E:POC/RL:OF/RC:C/CDP:H/TD:H/CR:M/IR:M/AR:M", 6.2, 4.9, 7.5},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.5, 7.5, 7.5},
+		{"AV:N/AC:L/Au:N/C:N/I:N/A:N", 0, 0, 0},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P/TD:N", 7.5, 7.5, 0},
+	})
+}
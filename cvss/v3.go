@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var v3Metrics = []metricDef{
+	{name: "AV", values: []string{"N", "A", "L", "P"}},
+	{name: "AC", values: []string{"L", "H"}},
+	{name: "PR", values: []string{"N", "L", "H"}},
+	{name: "UI", values: []string{"N", "R"}},
+	{name: "S", values: []string{"U", "C"}},
+	{name: "C", values: []string{"H", "L", "N"}},
+	{name: "I", values: []string{"H", "L", "N"}},
+	{name: "A", values: []string{"H", "L", "N"}},
+	{name: "E", values: []string{"X", "H", "F", "P", "U"}, optional: true},
+	{name: "RL", values: []string{"X", "U", "W", "T", "O"}, optional: true},
+	{name: "RC", values: []string{"X", "C", "R", "U"}, optional: true},
+	{name: "CR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "IR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "AR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "MAV", values: []string{"X", "N", "A", "L", "P"}, optional: true},
+	{name: "MAC", values: []string{"X", "L", "H"}, optional: true},
+	{name: "MPR", values: []string{"X", "N", "L", "H"}, optional: true},
+	{name: "MUI", values: []string{"X", "N", "R"}, optional: true},
+	{name: "MS", values: []string{"X", "U", "C"}, optional: true},
+	{name: "MC", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MI", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MA", values: []string{"X", "H", "L", "N"}, optional: true},
+}
+
+var v3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	"E":  {"X": 1, "H": 1, "F": 0.97, "P": 0.94, "U": 0.91},
+	"RL": {"X": 1, "U": 1, "W": 0.97, "T": 0.96, "O": 0.95},
+	"RC": {"X": 1, "C": 1, "R": 0.96, "U": 0.92},
+	"CR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+	"IR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+	"AR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+}
+
+// privileges required depend on the scope
+var v3PR = map[string]map[string]float64{
+	"U": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"C": {"N": 0.85, "L": 0.68, "H": 0.5},
+}
+
+// V3 is a CVSS v3.0 or v3.1 vector, e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+// Unset optional metrics are "X".
+type V3 struct {
+	metrics
+	version string
+}
+
+// ParseV3 parses a CVSS v3.x vector. Vectors without "CVSS:3.0/" or "CVSS:3.1/" prefix are parsed as v3.1.
+func ParseV3(s string) (*V3, error) {
+	s = strings.TrimSpace(s)
+	version := "3.1"
+	if rest, ok := strings.CutPrefix(s, "CVSS:"); ok {
+		version, s, _ = strings.Cut(rest, "/")
+		if version != "3.0" && version != "3.1" {
+			return nil, fmt.Errorf("%w: unsupported version %s", ErrInvalidVector, version)
+		}
+	}
+	m, err := parseMetrics(s, "CVSS:"+version, v3Metrics)
+	if err != nil {
+		return nil, err
+	}
+	return &V3{metrics: m, version: version}, nil
+}
+
+// Version returns "3.0" or "3.1".
+func (v *V3) Version() string { return v.version }
+
+func (v *V3) clone() Vector { return &V3{metrics: v.copy(), version: v.version} }
+
+// modified returns the value of the modified metric of a base metric, the base value if it is not set.
+func (v *V3) modified(metric string) string {
+	if m := v.Get("M" + metric); m != "X" {
+		return m
+	}
+	return v.Get(metric)
+}
+
+// BaseScore returns the base score.
+func (v *V3) BaseScore() float64 {
+	iss := 1 - (1-v3Weights["C"][v.Get("C")])*(1-v3Weights["I"][v.Get("I")])*(1-v3Weights["A"][v.Get("A")])
+	impact := 6.42 * iss
+	if v.Get("S") == "C" {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	return v.score(impact, v.exploitability(v.Get), v.Get("S"))
+}
+
+// TemporalScore returns the temporal score, which is the base score if no temporal metric is set.
+func (v *V3) TemporalScore() float64 {
+	return v.roundup(v.BaseScore() * v.temporalFactor())
+}
+
+// EnvironmentalScore returns the environmental score, which is the temporal score if no environmental metric is set.
+func (v *V3) EnvironmentalScore() float64 {
+	miss := math.Min(1-
+		(1-v3Weights["CR"][v.Get("CR")]*v3Weights["C"][v.modified("C")])*
+			(1-v3Weights["IR"][v.Get("IR")]*v3Weights["I"][v.modified("I")])*
+			(1-v3Weights["AR"][v.Get("AR")]*v3Weights["A"][v.modified("A")]), 0.915)
+	impact := 6.42 * miss
+	if v.modified("S") == "C" {
+		if v.version == "3.0" {
+			impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss-0.02, 15)
+		} else {
+			impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss*0.9731-0.02, 13)
+		}
+	}
+	if impact <= 0 {
+		return 0
+	}
+	return v.roundup(v.score(impact, v.exploitability(v.modified), v.modified("S")) * v.temporalFactor())
+}
+
+func (v *V3) temporalFactor() float64 {
+	return v3Weights["E"][v.Get("E")] * v3Weights["RL"][v.Get("RL")] * v3Weights["RC"][v.Get("RC")]
+}
+
+// exploitability returns the exploitability sub score of the base or modified metrics returned by get.
+func (v *V3) exploitability(get func(string) string) float64 {
+	return 8.22 * v3Weights["AV"][get("AV")] * v3Weights["AC"][get("AC")] * v3PR[get("S")][get("PR")] * v3Weights["UI"][get("UI")]
+}
+
+func (v *V3) score(impact, exploitability float64, scope string) float64 {
+	if impact <= 0 {
+		return 0
+	}
+	if scope == "C" {
+		return v.roundup(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return v.roundup(math.Min(impact+exploitability, 10))
+}
+
+// roundup rounds up to one decimal, v3.1 avoids floating point errors by rounding integers first.
+func (v *V3) roundup(f float64) float64 {
+	if v.version == "3.0" {
+		return math.Ceil(f*10) / 10
+	}
+	i := math.Round(f * 100000)
+	if math.Mod(i, 10000) == 0 {
+		return i / 100000
+	}
+	return (math.Floor(i/10000) + 1) / 10
+}
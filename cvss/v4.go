@@ -0,0 +1,283 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var v4Metrics = []metricDef{
+	{name: "AV", values: []string{"N", "A", "L", "P"}},
+	{name: "AC", values: []string{"L", "H"}},
+	{name: "AT", values: []string{"N", "P"}},
+	{name: "PR", values: []string{"N", "L", "H"}},
+	{name: "UI", values: []string{"N", "P", "A"}},
+	{name: "VC", values: []string{"H", "L", "N"}},
+	{name: "VI", values: []string{"H", "L", "N"}},
+	{name: "VA", values: []string{"H", "L", "N"}},
+	{name: "SC", values: []string{"H", "L", "N"}},
+	{name: "SI", values: []string{"H", "L", "N"}},
+	{name: "SA", values: []string{"H", "L", "N"}},
+	{name: "E", values: []string{"X", "A", "P", "U"}, optional: true},
+	{name: "CR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "IR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "AR", values: []string{"X", "H", "M", "L"}, optional: true},
+	{name: "MAV", values: []string{"X", "N", "A", "L", "P"}, optional: true},
+	{name: "MAC", values: []string{"X", "L", "H"}, optional: true},
+	{name: "MAT", values: []string{"X", "N", "P"}, optional: true},
+	{name: "MPR", values: []string{"X", "N", "L", "H"}, optional: true},
+	{name: "MUI", values: []string{"X", "N", "P", "A"}, optional: true},
+	{name: "MVC", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MVI", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MVA", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MSC", values: []string{"X", "H", "L", "N"}, optional: true},
+	{name: "MSI", values: []string{"X", "S", "H", "L", "N"}, optional: true},
+	{name: "MSA", values: []string{"X", "S", "H", "L", "N"}, optional: true},
+	{name: "S", values: []string{"X", "N", "P"}, optional: true},
+	{name: "AU", values: []string{"X", "N", "Y"}, optional: true},
+	{name: "R", values: []string{"X", "A", "U", "I"}, optional: true},
+	{name: "V", values: []string{"X", "D", "C"}, optional: true},
+	{name: "RE", values: []string{"X", "L", "M", "H"}, optional: true},
+	{name: "U", values: []string{"X", "Clear", "Green", "Amber", "Red"}, optional: true},
+}
+
+// v4Threat and v4Environmental are the metrics ignored by the CVSS-B and CVSS-BT scores.
+var (
+	v4Threat        = []string{"E"}
+	v4Environmental = []string{"CR", "IR", "AR", "MAV", "MAC", "MAT", "MPR", "MUI", "MVC", "MVI", "MVA", "MSC", "MSI", "MSA"}
+)
+
+// V4 is a CVSS v4.0 vector, e.g. "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N".
+// Unset optional metrics are "X". Its scores are computed from the MacroVector lookup table and
+// interpolation of the specification, supplemental metrics do not affect them.
+type V4 struct {
+	metrics
+}
+
+// ParseV4 parses a CVSS v4.0 vector.
+func ParseV4(s string) (*V4, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "CVSS:4.0/")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q lacks the CVSS:4.0 prefix", ErrInvalidVector, s)
+	}
+	m, err := parseMetrics(rest, "CVSS:4.0", v4Metrics)
+	if err != nil {
+		return nil, err
+	}
+	return &V4{m}, nil
+}
+
+// Version returns "4.0".
+func (v *V4) Version() string { return "4.0" }
+
+func (v *V4) clone() Vector { return &V4{v.copy()} }
+
+// BaseScore returns the CVSS-B score, ignoring threat and environmental metrics.
+func (v *V4) BaseScore() float64 {
+	return v.without(v4Threat, v4Environmental).score()
+}
+
+// TemporalScore returns the CVSS-BT score, ignoring environmental metrics.
+func (v *V4) TemporalScore() float64 {
+	return v.without(v4Environmental).score()
+}
+
+// EnvironmentalScore returns the CVSS-BTE score of all metrics.
+func (v *V4) EnvironmentalScore() float64 {
+	return v.score()
+}
+
+func (v *V4) without(groups ...[]string) *V4 {
+	c := &V4{v.copy()}
+	for _, g := range groups {
+		for _, m := range g {
+			delete(c.values, m)
+		}
+	}
+	return c
+}
+
+// effective returns the value a metric is scored with: the modified value if set,
+// and the worst case for unset threat metrics and security requirements.
+func (v *V4) effective(metric string) string {
+	switch metric {
+	case "E":
+		if e := v.Get("E"); e != "X" {
+			return e
+		}
+		return "A"
+	case "CR", "IR", "AR":
+		if r := v.Get(metric); r != "X" {
+			return r
+		}
+		return "H"
+	}
+	if m := v.Get("M" + metric); m != "" && m != "X" {
+		return m
+	}
+	return v.Get(metric)
+}
+
+// macroVector returns the equivalence classes EQ1 to EQ6 of v.
+func (v *V4) macroVector() [6]int {
+	m := v.effective
+	var eq [6]int
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+	if m("AC") != "L" || m("AT") != "N" {
+		eq[1] = 1
+	}
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+	switch {
+	case v.Get("MSI") == "S" || v.Get("MSA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+	eq[4] = map[string]int{"A": 0, "P": 1, "U": 2}[m("E")]
+	if !(m("CR") == "H" && m("VC") == "H" || m("IR") == "H" && m("VI") == "H" || m("AR") == "H" && m("VA") == "H") {
+		eq[5] = 1
+	}
+	return eq
+}
+
+// v4Lookup returns the score of a MacroVector, false if there is none, e.g. for a lower class than the lowest.
+func v4Lookup(eq [6]int) (float64, bool) {
+	var b strings.Builder
+	for _, e := range eq {
+		b.WriteString(strconv.Itoa(e))
+	}
+	score, ok := v4MacroVectorScores[b.String()]
+	return score, ok
+}
+
+func (v *V4) score() float64 {
+	m := v.effective
+	if m("VC") == "N" && m("VI") == "N" && m("VA") == "N" && m("SC") == "N" && m("SI") == "N" && m("SA") == "N" {
+		return 0
+	}
+	eq := v.macroVector()
+	value, _ := v4Lookup(eq)
+
+	// the next lower MacroVector of each EQ, EQ3 and EQ6 are scored together
+	lower := func(deltas ...int) (float64, bool) {
+		next := eq
+		for i := 0; i < len(deltas); i += 2 {
+			next[deltas[i]] += deltas[i+1]
+		}
+		return v4Lookup(next)
+	}
+	var lowerScores [5]float64
+	var lowerOK [5]bool
+	lowerScores[0], lowerOK[0] = lower(0, 1)
+	lowerScores[1], lowerOK[1] = lower(1, 1)
+	switch {
+	case eq[2] == 0 && eq[5] == 0:
+		// both paths lead down, take the higher score
+		left, leftOK := lower(5, 1)
+		right, rightOK := lower(2, 1)
+		lowerScores[2], lowerOK[2] = left, leftOK
+		if !leftOK || rightOK && right > left {
+			lowerScores[2], lowerOK[2] = right, rightOK
+		}
+	case eq[2] == 1 && eq[5] == 0:
+		lowerScores[2], lowerOK[2] = lower(5, 1)
+	case eq[2] == 2:
+		lowerScores[2], lowerOK[2] = lower(2, 1, 5, 1)
+	default:
+		lowerScores[2], lowerOK[2] = lower(2, 1)
+	}
+	lowerScores[3], lowerOK[3] = lower(3, 1)
+	lowerScores[4], lowerOK[4] = lower(4, 1)
+
+	// the severity distance of v from the first highest severity vector of its MacroVector it does not exceed
+	var distance map[string]float64
+	for _, highest := range v4MaxVectors(eq) {
+		distance = make(map[string]float64, len(highest))
+		exceeds := false
+		for _, metric := range v4DistanceMetrics {
+			distance[metric] = v4Levels[metric][m(metric)] - v4Levels[metric][highest[metric]]
+			if distance[metric] < 0 {
+				exceeds = true
+			}
+		}
+		if !exceeds {
+			break
+		}
+	}
+	current := [5]float64{
+		distance["AV"] + distance["PR"] + distance["UI"],
+		distance["AC"] + distance["AT"],
+		distance["VC"] + distance["VI"] + distance["VA"] + distance["CR"] + distance["IR"] + distance["AR"],
+		distance["SC"] + distance["SI"] + distance["SA"],
+		0,
+	}
+	depth := [5]float64{
+		v4MaxSeverity.eq1[eq[0]],
+		v4MaxSeverity.eq2[eq[1]],
+		v4MaxSeverity.eq3eq6[eq[2]][eq[5]],
+		v4MaxSeverity.eq4[eq[3]],
+		v4MaxSeverity.eq5[eq[4]],
+	}
+
+	// the mean of the distances proportional to the available distance to the next lower MacroVectors
+	const step = 0.1
+	var sum float64
+	n := 0
+	for i := range current {
+		if !lowerOK[i] {
+			continue
+		}
+		n++
+		sum += (value - lowerScores[i]) * (current[i] / (depth[i] * step))
+	}
+	if n > 0 {
+		value -= sum / float64(n)
+	}
+	return round1(math.Max(0, math.Min(10, value)))
+}
+
+// v4MaxVectors returns the highest severity vectors of a MacroVector.
+func v4MaxVectors(eq [6]int) []map[string]string {
+	var vectors []map[string]string
+	for _, eq1 := range v4MaxComposed.eq1[eq[0]] {
+		for _, eq2 := range v4MaxComposed.eq2[eq[1]] {
+			for _, eq3eq6 := range v4MaxComposed.eq3eq6[eq[2]][eq[5]] {
+				for _, eq4 := range v4MaxComposed.eq4[eq[3]] {
+					for _, eq5 := range v4MaxComposed.eq5[eq[4]] {
+						vector := make(map[string]string)
+						for _, part := range strings.Split(eq1+eq2+eq3eq6+eq4+eq5, "/") {
+							if name, value, ok := strings.Cut(part, ":"); ok {
+								vector[name] = value
+							}
+						}
+						vectors = append(vectors, vector)
+					}
+				}
+			}
+		}
+	}
+	return vectors
+}
@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cvss
+
+import "testing"
+
+func TestV4_Scores(t *testing.T) {
+	testScores(t, []scoreTest{
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 9.3, 9.3, 9.3},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", 10, 10, 10},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 8.7, 8.7, 8.7},
+		{"CVSS:4.0/AV:L/AC:L/AT:N/PR:N/UI:P/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 8.5, 8.5, 8.5},
+		// EQ3=1 and EQ6=0 steps down to EQ6=1 only
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:N/VA:N/SC:N/SI:N/SA:N", 8.7, 8.7, 8.7},
+		// EQ3=2 has no lower MacroVector
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:L/VI:N/VA:N/SC:N/SI:N/SA:N", 6.9, 6.9, 6.9},
+		{"CVSS:4.0/AV:P/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N", 0, 0, 0},
+		// threat metrics lower CVSS-BT
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:P", 9.3, 8.9, 8.9},
+		// supplemental metrics do not change the scores
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/S:P/AU:Y/U:Red", 9.3, 9.3, 9.3},
+		// a safety impact makes the asset as severe as it gets
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MSI:S", 9.3, 9.3, 10},
+		// environmental metrics matching the base metrics do not change the score
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MAV:N/MPR:L/CR:H", 8.7, 8.7, 8.7},
+	})
+}
+
+func TestV4_MacroVector(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   [6]int
+	}{
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", [6]int{0, 0, 0, 2, 0, 0}},
+		{"CVSS:4.0/AV:P/AC:H/AT:P/PR:H/UI:A/VC:L/VI:L/VA:N/SC:H/SI:N/SA:N/E:U", [6]int{2, 1, 2, 1, 2, 1}},
+		{"CVSS:4.0/AV:A/AC:L/AT:N/PR:N/UI:P/VC:H/VI:L/VA:N/SC:N/SI:N/SA:N/MSA:S/CR:L", [6]int{1, 0, 1, 0, 0, 1}},
+	}
+	for _, test := range tests {
+		v, err := ParseV4(test.vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.macroVector(); got != test.want {
+			t.Errorf("%s: MacroVector %v, want %v", test.vector, got, test.want)
+		}
+	}
+	if len(v4MacroVectorScores) != 270 {
+		t.Errorf("Got %d MacroVector scores, want 270", len(v4MacroVectorScores))
+	}
+}
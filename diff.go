@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FindingKey identifies a finding across analysis snapshots.
+type FindingKey struct {
+	PluginID   ID
+	Repository ID
+	// Host is the unique host identity, e.g. "uuid:..." or "ip:10.0.0.1,dnsName:host.example.com"
+	Host     string
+	Port     Int
+	Protocol string
+}
+
+func (k FindingKey) String() string {
+	return fmt.Sprintf("plugin %d repository %d %s %d/%s", k.PluginID, k.Repository, k.Host, k.Port, strings.ToLower(k.Protocol))
+}
+
+// Finding is implemented by results which can be compared across snapshots.
+type Finding interface {
+	FindingKey() FindingKey
+	SeverityLevel() SeverityLevel
+}
+
+// FindingKey returns the key of a, its host is identified by the fields of HostUniqueness,
+// e.g. "repositoryID,ip,dnsName", by the UUID or else by the IP address and DNS name.
+func (a Analysis) FindingKey() FindingKey {
+	return FindingKey{
+		PluginID:   a.PluginID,
+		Repository: a.Repository.ID,
+		Host:       a.hostIdentity(),
+		Port:       a.Port,
+		Protocol:   strings.ToUpper(a.Protocol),
+	}
+}
+
+func (a Analysis) hostIdentity() string {
+	values := map[string]string{"ip": a.IP, "dnsName": a.DNSName, "uuid": a.UUID, "macAddress": a.MACAddress, "netBiosName": a.NetBiosName}
+	var fields []string
+	for _, f := range strings.Split(a.HostUniqueness, ",") {
+		if _, ok := values[strings.TrimSpace(f)]; ok {
+			fields = append(fields, strings.TrimSpace(f))
+		}
+	}
+	if len(fields) == 0 {
+		fields = []string{"ip", "dnsName"}
+		if a.UUID != "" {
+			fields = []string{"uuid"}
+		}
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f + ":" + strings.ToLower(values[f])
+	}
+	return strings.Join(parts, ",")
+}
+
+// SeverityLevel returns the severity of a.
+func (a Analysis) SeverityLevel() SeverityLevel {
+	return SeverityLevel(a.Severity.ID)
+}
+
+// SeverityChange is a finding present in both snapshots with a different severity.
+type SeverityChange[T any] struct {
+	Before T
+	After  T
+}
+
+// DiffSummary counts the findings of a SnapshotDiff. The per severity counts are indexed by SeverityLevel.
+type DiffSummary struct {
+	Before          int
+	After           int
+	New             int
+	Resolved        int
+	Persistent      int
+	SeverityChanged int
+
+	NewBySeverity      [SeverityCritical + 1]int
+	ResolvedBySeverity [SeverityCritical + 1]int
+}
+
+// SnapshotDiff is the difference of two analysis snapshots.
+type SnapshotDiff[T any] struct {
+	// New are the findings only present in the later snapshot
+	New []T
+	// Resolved are the findings only present in the earlier snapshot
+	Resolved []T
+	// Persistent are the findings present in both snapshots, as of the later one
+	Persistent []T
+	// SeverityChanged are the persistent findings whose severity changed
+	SeverityChanged []SeverityChange[T]
+	Summary         DiffSummary
+}
+
+// Diff compares the findings of two snapshots, e.g. last week's and today's listvuln results.
+// Findings are matched by their FindingKey, the first of duplicate keys is used.
+// New and persistent findings are in the order of after, resolved ones in the order of before.
+func Diff[T Finding](before, after []T) *SnapshotDiff[T] {
+	return DiffFunc(before, after, T.FindingKey, T.SeverityLevel)
+}
+
+// DiffFunc is Diff for result types without a FindingKey, e.g. summaries keyed by IP address.
+func DiffFunc[T any](before, after []T, key func(T) FindingKey, severity func(T) SeverityLevel) *SnapshotDiff[T] {
+	d := &SnapshotDiff[T]{Summary: DiffSummary{Before: len(before), After: len(after)}}
+	earlier := make(map[FindingKey]T, len(before))
+	for _, f := range before {
+		k := key(f)
+		if _, dup := earlier[k]; !dup {
+			earlier[k] = f
+		}
+	}
+
+	seen := make(map[FindingKey]bool, len(after))
+	for _, f := range after {
+		k := key(f)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		prev, ok := earlier[k]
+		if !ok {
+			d.New = append(d.New, f)
+			countSeverity(&d.Summary.NewBySeverity, severity(f))
+			continue
+		}
+		d.Persistent = append(d.Persistent, f)
+		if severity(prev) != severity(f) {
+			d.SeverityChanged = append(d.SeverityChanged, SeverityChange[T]{Before: prev, After: f})
+		}
+	}
+	for _, f := range before {
+		k := key(f)
+		if seen[k] {
+			continue
+		}
+		// mark resolved duplicates as seen as well, so they are reported once
+		seen[k] = true
+		d.Resolved = append(d.Resolved, f)
+		countSeverity(&d.Summary.ResolvedBySeverity, severity(f))
+	}
+
+	d.Summary.New = len(d.New)
+	d.Summary.Resolved = len(d.Resolved)
+	d.Summary.Persistent = len(d.Persistent)
+	d.Summary.SeverityChanged = len(d.SeverityChanged)
+	return d
+}
+
+func countSeverity(counts *[SeverityCritical + 1]int, l SeverityLevel) {
+	if l >= SeverityInfo && l <= SeverityCritical {
+		counts[l]++
+	}
+}
+
+// Snapshot is a set of analysis results taken at a point in time, which can be saved to disk.
+type Snapshot[T any] struct {
+	Time    time.Time `json:"time"`
+	Tool    Tool      `json:"tool,omitempty"`
+	Results []T       `json:"results"`
+}
+
+// NewSnapshot returns a snapshot of results taken now.
+func NewSnapshot[T ToolResult](results []T) *Snapshot[T] {
+	var zero T
+	return &Snapshot[T]{Time: time.Now().UTC(), Tool: zero.Tool(), Results: results}
+}
+
+// Save writes the snapshot as JSON to path. The file is replaced atomically.
+func (s *Snapshot[T]) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := json.NewEncoder(tmp).Encode(s); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads a snapshot written by Snapshot.Save.
+func LoadSnapshot[T any](path string) (*Snapshot[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := new(Snapshot[T])
+	if err := json.NewDecoder(f).Decode(s); err != nil {
+		return nil, fmt.Errorf("tenable: invalid snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func finding(pluginID ID, ip string, port Int, severity ID) Analysis {
+	return Analysis{
+		PluginID:   pluginID,
+		IP:         ip,
+		Port:       port,
+		Protocol:   "TCP",
+		Severity:   Severity{ID: severity},
+		Repository: Repository{ID: 1},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := []Analysis{
+		finding(19506, "10.0.0.1", 0, 0),
+		finding(119500, "10.0.0.1", 443, 3),
+		finding(57582, "10.0.0.2", 22, 2),
+		finding(57582, "10.0.0.2", 22, 2), // duplicate
+	}
+	after := []Analysis{
+		finding(119500, "10.0.0.1", 443, 4),
+		finding(19506, "10.0.0.1", 0, 0),
+		finding(156032, "10.0.0.3", 8080, 4),
+	}
+	after[1].Protocol = "tcp" // protocols are compared case insensitively
+
+	d := Diff(before, after)
+	want := DiffSummary{Before: 4, After: 3, New: 1, Resolved: 1, Persistent: 2, SeverityChanged: 1}
+	want.NewBySeverity[SeverityCritical] = 1
+	want.ResolvedBySeverity[SeverityMedium] = 1
+	if d.Summary != want {
+		t.Errorf("Summary %+v\nwant %+v", d.Summary, want)
+	}
+	if d.New[0].PluginID != 156032 || d.Resolved[0].PluginID != 57582 {
+		t.Errorf("Unexpected new %v or resolved %v", d.New, d.Resolved)
+	}
+	if c := d.SeverityChanged[0]; c.Before.Severity.ID != 3 || c.After.Severity.ID != 4 {
+		t.Errorf("Unexpected severity change %+v", c)
+	}
+}
+
+func TestAnalysis_FindingKey(t *testing.T) {
+	a := Analysis{PluginID: 1, IP: "10.0.0.1", DNSName: "Host.example.com", UUID: "u-1"}
+	if host := a.FindingKey().Host; host != "uuid:u-1" {
+		t.Errorf("Host %q, want uuid:u-1", host)
+	}
+	a.HostUniqueness = "repositoryID,ip,dnsName"
+	if host := a.FindingKey().Host; host != "ip:10.0.0.1,dnsName:host.example.com" {
+		t.Errorf("Host %q", host)
+	}
+	// a moved host is a different finding
+	b := a
+	b.IP = "10.0.0.9"
+	if d := Diff([]Analysis{a}, []Analysis{b}); d.Summary.New != 1 || d.Summary.Resolved != 1 {
+		t.Errorf("Unexpected summary %+v", d.Summary)
+	}
+}
+
+func TestDiffFunc(t *testing.T) {
+	key := func(s IPSummary) FindingKey { return FindingKey{Host: s.IP, Repository: s.Repository.ID} }
+	severity := func(s IPSummary) SeverityLevel {
+		if s.SeverityCritical > 0 {
+			return SeverityCritical
+		}
+		return SeverityInfo
+	}
+	before := []IPSummary{{IP: "10.0.0.1"}}
+	after := []IPSummary{{IP: "10.0.0.1", SeverityCounts: SeverityCounts{SeverityCritical: 2}}, {IP: "10.0.0.2"}}
+	d := DiffFunc(before, after, key, severity)
+	if d.Summary.New != 1 || d.Summary.SeverityChanged != 1 || d.Summary.Resolved != 0 {
+		t.Errorf("Unexpected summary %+v", d.Summary)
+	}
+}
+
+func TestSnapshot_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "week42.json")
+	snap := NewSnapshot([]VulnDetail{{Analysis: finding(119500, "10.0.0.1", 443, 4), Synopsis: "Remote code execution"}})
+	if err := snap.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSnapshot[VulnDetail](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Tool != ToolVulnDetails || !loaded.Time.Equal(snap.Time) || len(loaded.Results) != 1 {
+		t.Fatalf("Unexpected snapshot %+v", loaded)
+	}
+	if d := Diff(snap.Results, loaded.Results); d.Summary.Persistent != 1 || d.Summary.SeverityChanged != 0 {
+		t.Errorf("Expected the loaded snapshot to match, got %+v", d.Summary)
+	}
+	if loaded.Results[0].Synopsis != "Remote code execution" {
+		t.Errorf("Synopsis %q", loaded.Results[0].Synopsis)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files, got %d entries", len(entries))
+	}
+	if _, err := LoadSnapshot[Analysis](filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
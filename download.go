@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+)
+
+// DownloadFormat is the file format of an analysis download.
+type DownloadFormat string
+
+// Formats of analysis downloads.
+const (
+	DownloadCSV DownloadFormat = "csv"
+	DownloadPDF DownloadFormat = "pdf"
+)
+
+var downloadMediaTypes = map[DownloadFormat]string{
+	DownloadCSV: "text/csv",
+	DownloadPDF: "application/pdf",
+}
+
+// DownloadOptions configures an analysis download.
+type DownloadOptions struct {
+	// Format is the file format, DownloadCSV if empty
+	Format DownloadFormat
+	// Columns of the file, they replace the columns of the body if set. At least one column is required.
+	Columns []Column
+}
+
+// DownloadError is a failure while reading a download, after Offset bytes were read successfully.
+type DownloadError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("tenable: download failed after %d bytes: %v", e.Offset, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// downloadBody is the request of /rest/analysis/download.
+type downloadBody struct {
+	AnalysisBody
+	Format DownloadFormat `json:"format,omitempty"`
+}
+
+// DownloadWithContext downloads the results of the analysis query in body as a file, e.g. CSV with the chosen columns.
+// The returned io.ReadCloser streams the file without buffering it and must be closed.
+// Errors of the request, including error envelopes sent instead of the file, are returned right away;
+// failures while reading the stream, e.g. a connection reset or a truncated body, are returned by Read as *DownloadError.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) DownloadWithContext(ctx context.Context, body AnalysisBody, opts *DownloadOptions) (io.ReadCloser, *Response, error) {
+	format := DownloadCSV
+	if opts != nil && opts.Format != "" {
+		format = opts.Format
+	}
+	mediaType, ok := downloadMediaTypes[format]
+	if !ok {
+		return nil, nil, fmt.Errorf("tenable: unknown download format %q", format)
+	}
+	if opts != nil && len(opts.Columns) > 0 {
+		body.Columns = opts.Columns
+	}
+	if columns, err := body.columnList(); err != nil {
+		return nil, nil, err
+	} else if len(columns) == 0 {
+		return nil, nil, errors.New("tenable: a download needs at least one column")
+	}
+	body, err := body.normalize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequestWithContext(ctx, "POST", "/rest/analysis/download", downloadBody{AnalysisBody: body, Format: format})
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", mediaType+", application/json")
+	resp, err := s.client.doStream(req)
+	if err != nil {
+		return nil, resp, NewTenableError(resp, err)
+	}
+
+	// Tenable.sc answers failed downloads with an error envelope and status 200
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct == "application/json" {
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, resp, err
+		}
+		if apiErr := newAPIError(resp.Response, raw); apiErr != nil {
+			return nil, resp, apiErr
+		}
+		return nil, resp, fmt.Errorf("tenable: expected a %s download, got %s", format, ct)
+	}
+	return &downloadReader{body: resp.Body, size: resp.ContentLength}, resp, nil
+}
+
+// Download wraps DownloadWithContext using the background context.
+func (s *AnalysisService) Download(body AnalysisBody, opts *DownloadOptions) (io.ReadCloser, *Response, error) {
+	return s.DownloadWithContext(context.Background(), body, opts)
+}
+
+// downloadReader reports read failures of the download with the number of bytes read so far.
+type downloadReader struct {
+	body io.ReadCloser
+	// size is the announced length of the body, -1 if unknown
+	size int64
+	n    int64
+}
+
+func (r *downloadReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.n += int64(n)
+	switch {
+	case err == io.EOF && r.size >= 0 && r.n < r.size:
+		err = io.ErrUnexpectedEOF
+	case err == nil || err == io.EOF:
+		return n, err
+	}
+	return n, &DownloadError{Offset: r.n, Err: err}
+}
+
+func (r *downloadReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestAnalysisService_Download(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis/download", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		if accept := r.Header.Get("Accept"); accept != "text/csv, application/json" {
+			t.Errorf("Accept: %q", accept)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		columns, _ := json.Marshal(body["columns"])
+		if body["format"] != "csv" || body["sourceType"] != "cumulative" || string(columns) != `[{"name":"pluginID"},{"name":"ip"}]` {
+			t.Errorf("Unexpected body %v", body)
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		fmt.Fprint(w, "\"Plugin\",\"IP Address\"\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "\"19506\",\"10.0.0.1\"\n")
+	})
+
+	rc, _, err := testClient.Analysis.Download(AnalysisBody{Query: AnalysisQuery{Tool: "listvuln"}}, &DownloadOptions{Columns: Columns("pluginID", "ip")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	raw, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\"Plugin\",\"IP Address\"\n\"19506\",\"10.0.0.1\"\n"; string(raw) != want {
+		t.Errorf("Got %q, want %q", raw, want)
+	}
+}
+
+func TestAnalysisService_DownloadReportsOnClose(t *testing.T) {
+	setup()
+	defer teardown()
+	const csv = "\"Plugin\",\"IP Address\"\n\"19506\",\"10.0.0.1\"\n"
+	testMux.HandleFunc("/rest/analysis/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		fmt.Fprint(w, csv)
+	})
+	var infos []RequestInfo
+	testClient.afterResponse = append(testClient.afterResponse, func(ctx context.Context, info *RequestInfo) { infos = append(infos, *info) })
+
+	rc, _, err := testClient.Analysis.Download(AnalysisBody{Query: AnalysisQuery{Tool: "listvuln"}}, &DownloadOptions{Columns: Columns("pluginID", "ip")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("Expected no report before the download is closed, got %+v", infos)
+	}
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	if len(infos) != 1 || infos[0].BytesReceived != int64(len(csv)) {
+		t.Errorf("Expected the whole download to be reported, got %+v", infos)
+	}
+}
+
+func TestAnalysisService_DownloadErrors(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"regular","response":"","error_code":146,"error_msg":"Invalid parameters."}`))
+	})
+
+	body := AnalysisBody{Columns: Columns("pluginID")}
+	if _, _, err := testClient.Analysis.Download(body, nil); !errors.Is(err, &APIError{Code: 146}) {
+		t.Errorf("Expected error_code 146, got %v", err)
+	}
+	if _, _, err := testClient.Analysis.Download(AnalysisBody{}, nil); err == nil {
+		t.Error("Expected an error without columns")
+	}
+	if _, _, err := testClient.Analysis.Download(body, &DownloadOptions{Format: "xlsx"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestAnalysisService_DownloadTruncated(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis/download", func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: text/csv\r\nContent-Length: 1000\r\n\r\n\"Plugin\"\n\"19506\"\n")
+		buf.Flush()
+	})
+
+	rc, _, err := testClient.Analysis.Download(AnalysisBody{Columns: Columns("pluginID")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	raw, err := io.ReadAll(rc)
+	var dlErr *DownloadError
+	if !errors.As(err, &dlErr) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected a *DownloadError wrapping io.ErrUnexpectedEOF, got %v", err)
+	}
+	if dlErr.Offset != int64(len(raw)) || len(raw) != 17 {
+		t.Errorf("Offset %d, read %d bytes", dlErr.Offset, len(raw))
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+)
+
+// Envelope is the standard wrapper around every Tenable.sc API response.
+//
+//	{
+//		"type": "regular",
+//		"response": {...},
+//		"error_code": 0,
+//		"error_msg": "",
+//		"warnings": [],
+//		"timestamp": 1657818772
+//	}
+type Envelope[T any] struct {
+	Type      string   `json:"type,omitempty"`
+	Response  T        `json:"response"`
+	ErrorCode int      `json:"error_code"`
+	ErrorMsg  string   `json:"error_msg"`
+	Warnings  []string `json:"warnings"`
+	Timestamp int      `json:"timestamp"`
+}
+
+// pageValues returns the paging information of the response, if it has any.
+func (e *Envelope[T]) pageValues() (startAt, maxResults, total int, ok bool) {
+	if p, isPager := interface{}(&e.Response).(pager); isPager {
+		return p.pageValues()
+	}
+	return 0, 0, 0, false
+}
+
+// get performs a GET request against endpoint and decodes the envelope of the response.
+func get[T any](ctx context.Context, c *Client, endpoint string) (*Envelope[T], *Response, error) {
+	return do[T](ctx, c, "GET", endpoint, nil)
+}
+
+// post performs a POST request with the JSON encoded body against endpoint and decodes the envelope of the response.
+func post[T any](ctx context.Context, c *Client, endpoint string, body interface{}) (*Envelope[T], *Response, error) {
+	return do[T](ctx, c, "POST", endpoint, body)
+}
+
+// patch performs a PATCH request with the JSON encoded body against endpoint and decodes the envelope of the response.
+func patch[T any](ctx context.Context, c *Client, endpoint string, body interface{}) (*Envelope[T], *Response, error) {
+	return do[T](ctx, c, "PATCH", endpoint, body)
+}
+
+// delete performs a DELETE request against endpoint, discarding the response envelope.
+func (c *Client) delete(ctx context.Context, endpoint string) (*Response, error) {
+	_, resp, err := do[interface{}](ctx, c, "DELETE", endpoint, nil)
+	return resp, err
+}
+
+func do[T any](ctx context.Context, c *Client, method, endpoint string, body interface{}) (*Envelope[T], *Response, error) {
+	req, err := c.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envelope := new(Envelope[T])
+	resp, err := c.Do(req, envelope)
+	if err != nil {
+		return nil, resp, NewTenableError(resp, err)
+	}
+	return envelope, resp, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type testScan struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestEnvelopeHelpers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/scan/1", func(w http.ResponseWriter, r *http.Request) {
+		testRequestURL(t, r, "/rest/scan/1")
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"type":"regular","response":{"id":"1","name":"weekly"},"error_code":0,"error_msg":"","warnings":["w1"],"timestamp":1657818772}`)
+		case "PATCH", "POST":
+			var scan testScan
+			if err := json.NewDecoder(r.Body).Decode(&scan); err != nil {
+				t.Error(err)
+			}
+			scan.ID = "1"
+			json.NewEncoder(w).Encode(Envelope[testScan]{Type: "regular", Response: scan})
+		case "DELETE":
+			fmt.Fprint(w, `{"type":"regular","response":"","error_code":0}`)
+		}
+	})
+	testMux.HandleFunc("/rest/scan/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":147,"error_msg":"Scan #2 not found"}`)
+	})
+
+	ctx := context.Background()
+	got, resp, err := get[testScan](ctx, testClient, "/rest/scan/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status code: %d", resp.StatusCode)
+	}
+	if got.Type != "regular" || got.Response.Name != "weekly" || got.Timestamp != 1657818772 || len(got.Warnings) != 1 {
+		t.Errorf("Unexpected envelope %+v", got)
+	}
+
+	got, _, err = patch[testScan](ctx, testClient, "/rest/scan/1", testScan{Name: "daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Response.Name != "daily" || got.Response.ID != "1" {
+		t.Errorf("Unexpected response %+v", got.Response)
+	}
+
+	if _, _, err = post[testScan](ctx, testClient, "/rest/scan/1", testScan{Name: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = testClient.delete(ctx, "/rest/scan/1"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = get[testScan](ctx, testClient, "/rest/scan/2")
+	if !errors.Is(err, &APIError{Code: 147}) {
+		t.Errorf("Expected error_code 147, got %v", err)
+	}
+}
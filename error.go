@@ -8,59 +8,123 @@ package tenable
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"strings"
 
-	"github.com/pkg/errors"
+	pkgerrors "github.com/pkg/errors"
 )
 
-// Error message from Tenable
-type Error struct {
-	HTTPError     error
-	ErrorMessages []string          `json:"errorMessages"`
-	Errors        map[string]string `json:"errors"`
+var (
+	// ErrUnauthorized matches API errors answered with HTTP 401 (e.g. an invalid token or API key).
+	ErrUnauthorized = &APIError{StatusCode: http.StatusUnauthorized}
+	// ErrForbidden matches API errors answered with HTTP 403.
+	ErrForbidden = &APIError{StatusCode: http.StatusForbidden}
+	// ErrNotFound matches API errors answered with HTTP 404.
+	ErrNotFound = &APIError{StatusCode: http.StatusNotFound}
+)
+
+// APIError is returned whenever Tenable reports a failure, either through an HTTP status code
+// outside the 200 range or through a non-zero error_code in the standard response envelope.
+// Tenable.sc frequently answers HTTP 200 with an error_code, so callers should use
+// errors.As to inspect the code rather than relying on the status alone.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int `json:"-"`
+	// Method and Endpoint identify the request that failed, e.g. "POST" and "/rest/analysis"
+	Method   string `json:"-"`
+	Endpoint string `json:"-"`
+
+	// Fields of the Tenable response envelope
+	Type      string   `json:"type"`
+	Code      int      `json:"error_code"`
+	Message   string   `json:"error_msg"`
+	Warnings  []string `json:"warnings"`
+	Timestamp int      `json:"timestamp"`
+}
+
+// Error is a short string representing the error
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString("tenable: ")
+	if e.Endpoint != "" {
+		fmt.Fprintf(&b, "%s %s: ", e.Method, e.Endpoint)
+	}
+	if e.Code != 0 {
+		fmt.Fprintf(&b, "error_code %d", e.Code)
+	} else {
+		b.WriteString("request failed")
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", strings.TrimSpace(e.Message))
+	}
+	fmt.Fprintf(&b, " (HTTP %d)", e.StatusCode)
+	return b.String()
+}
+
+// Is reports whether target is an *APIError matching e.
+// Only the non-zero Code and StatusCode of target are compared, so
+// errors.Is(err, &APIError{Code: 143}) matches any API error with error_code 143
+// and errors.Is(err, ErrUnauthorized) matches any HTTP 401.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Code == 0 && t.StatusCode == 0 {
+		return false
+	}
+	return (t.Code == 0 || t.Code == e.Code) &&
+		(t.StatusCode == 0 || t.StatusCode == e.StatusCode)
+}
+
+// newAPIError builds an *APIError for r from the envelope in body.
+// It returns nil if the body is a valid envelope without an error and the status code is in the 200 range.
+func newAPIError(r *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: r.StatusCode}
+	if r.Request != nil {
+		apiErr.Method = r.Request.Method
+		apiErr.Endpoint = r.Request.URL.Path
+	}
+	// bodies which are no envelope (HTML error pages, CSV downloads, ...) carry no error_code
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			*apiErr = APIError{StatusCode: apiErr.StatusCode, Method: apiErr.Method, Endpoint: apiErr.Endpoint}
+			if r.StatusCode >= 300 {
+				apiErr.Message = string(body)
+			}
+		}
+	}
+	if apiErr.Code == 0 && 200 <= r.StatusCode && r.StatusCode <= 299 {
+		return nil
+	}
+	return apiErr
 }
 
-// NewTenableError creates a new Tenable Error
+// NewTenableError creates a new Tenable Error.
+// Errors which already are an *APIError are returned unchanged.
 func NewTenableError(resp *Response, httpError error) error {
+	var apiErr *APIError
+	if errors.As(httpError, &apiErr) {
+		return httpError
+	}
 	if resp == nil {
-		return errors.Wrap(httpError, "No response returned")
+		return pkgerrors.Wrap(httpError, "No response returned")
 	}
 
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return errors.Wrap(err, httpError.Error())
+		return pkgerrors.Wrap(err, httpError.Error())
 	}
-	jerr := Error{HTTPError: httpError}
-	contentType := resp.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "application/json") {
-		err = json.Unmarshal(body, &jerr)
-		if err != nil {
-			httpError = errors.Wrap(errors.New("could not parse JSON"), httpError.Error())
-			return errors.Wrap(err, httpError.Error())
-		}
-	} else {
-		if httpError == nil {
-			return fmt.Errorf("got response status %s:%s", resp.Status, string(body))
-		}
-		return errors.Wrap(httpError, fmt.Sprintf("%s: %s", resp.Status, string(body)))
+	if apiErr := newAPIError(resp.Response, body); apiErr != nil {
+		return apiErr
 	}
-
-	return &jerr
-}
-
-// Error is a short string representing the error
-func (e *Error) Error() string {
-	if len(e.ErrorMessages) > 0 {
-		// return fmt.Sprintf("%v", e.HTTPError)
-		return fmt.Sprintf("%s: %v", e.ErrorMessages[0], e.HTTPError)
-	}
-	if len(e.Errors) > 0 {
-		for key, value := range e.Errors {
-			return fmt.Sprintf("%s - %s: %v", key, value, e.HTTPError)
-		}
+	if httpError == nil {
+		return fmt.Errorf("got response status %s:%s", resp.Status, string(body))
 	}
-	return e.HTTPError.Error()
+	return pkgerrors.Wrap(httpError, fmt.Sprintf("%s: %s", resp.Status, string(body)))
 }
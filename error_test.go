@@ -0,0 +1,78 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError_ErrorCodeInEnvelope(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":143,"error_msg":"Unable to retrieve user.\n","warnings":["deprecated"],"timestamp":1657818772}`)
+	})
+
+	user, resp, err := testClient.CurrentUser.Get()
+	if err == nil {
+		t.Fatalf("Expected an error, got user %+v", user)
+	}
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the HTTP 200 response to be returned, got %+v", resp)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %T", err)
+	}
+	if apiErr.Code != 143 || apiErr.Message != "Unable to retrieve user.\n" || apiErr.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+	if apiErr.Method != "GET" || apiErr.Endpoint != "/rest/currentUser" {
+		t.Errorf("Unexpected endpoint %s %s", apiErr.Method, apiErr.Endpoint)
+	}
+	if len(apiErr.Warnings) != 1 || apiErr.Warnings[0] != "deprecated" {
+		t.Errorf("Unexpected warnings %v", apiErr.Warnings)
+	}
+	if !errors.Is(err, &APIError{Code: 143}) {
+		t.Error("Expected errors.Is to match error_code 143")
+	}
+	if errors.Is(err, &APIError{Code: 1}) {
+		t.Error("Expected errors.Is not to match error_code 1")
+	}
+	if want := "tenable: GET /rest/currentUser: error_code 143: Unable to retrieve user. (HTTP 200)"; err.Error() != want {
+		t.Errorf("Error: %q, want %q", err.Error(), want)
+	}
+}
+
+func TestAPIError_StatusCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testMux.HandleFunc("/rest/repository", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":74,"error_msg":"Invalid token","warnings":[],"timestamp":1657818772}`)
+	})
+
+	_, _, err := testClient.Repository.Get("All", "")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("Expected errors.Is not to match ErrNotFound")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 74 {
+		t.Errorf("Expected error_code 74, got %v", err)
+	}
+}
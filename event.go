@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"fmt"
+)
+
+// Source types of event analysis.
+const (
+	// SourceLCE queries the active event data of the Log Correlation Engines
+	SourceLCE SourceType = "lce"
+	// SourceArchive queries archived event data, LCEID and View are required
+	SourceArchive SourceType = "archive"
+)
+
+// EventTool is the analysis tool of an event query.
+type EventTool string
+
+// Analysis tools of event queries.
+const (
+	EventToolSumType  EventTool = "sumtype"
+	EventToolSumEvent EventTool = "sumevent"
+	EventToolSumIP    EventTool = "sumip"
+	EventToolSumUser  EventTool = "sumuser"
+	EventToolSyslog   EventTool = "syslog"
+	EventToolListData EventTool = "listdata"
+)
+
+// EventResult is implemented by the result types of the event tools.
+type EventResult interface {
+	EventTool() EventTool
+}
+
+// EventBody is an event (LCE) analysis query. The tool is set from the result type.
+type EventBody struct {
+	// SourceType is SourceLCE if empty
+	SourceType  SourceType
+	StartOffset int64
+	EndOffset   int64
+	Filters     []AnalysisFilter
+	// LCEID and View (the archive silo) select the data of SourceArchive queries
+	LCEID ID
+	View  string
+}
+
+// LCE is a Log Correlation Engine.
+type LCE struct {
+	ID   ID     `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// EventTypeSummary is a result of the sumtype tool, a normalized event type and its number of events.
+type EventTypeSummary struct {
+	Type  string `json:"type"`
+	Count Int    `json:"count"`
+}
+
+// EventTool returns EventToolSumType.
+func (EventTypeSummary) EventTool() EventTool { return EventToolSumType }
+
+// EventSummary is a result of the sumevent tool, a normalized event and its number of events.
+type EventSummary struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"`
+	Count Int    `json:"count"`
+}
+
+// EventTool returns EventToolSumEvent.
+func (EventSummary) EventTool() EventTool { return EventToolSumEvent }
+
+// EventIPSummary is a result of the sumip tool of event queries.
+type EventIPSummary struct {
+	Address string `json:"address"`
+	Count   Int    `json:"count"`
+}
+
+// EventTool returns EventToolSumIP.
+func (EventIPSummary) EventTool() EventTool { return EventToolSumIP }
+
+// EventUserSummary is a result of the sumuser tool.
+type EventUserSummary struct {
+	User  string `json:"user"`
+	Count Int    `json:"count"`
+}
+
+// EventTool returns EventToolSumUser.
+func (EventUserSummary) EventTool() EventTool { return EventToolSumUser }
+
+// SyslogEvent is a result of the syslog tool, a raw log line.
+type SyslogEvent struct {
+	Timestamp Epoch  `json:"timestamp"`
+	Message   string `json:"message"`
+	LCE       LCE    `json:"lce,omitempty"`
+	Sensor    string `json:"sensor,omitempty"`
+}
+
+// EventTool returns EventToolSyslog.
+func (SyslogEvent) EventTool() EventTool { return EventToolSyslog }
+
+// EventData is a result of the listdata tool, a normalized event.
+type EventData struct {
+	ID              string `json:"id,omitempty"`
+	Timestamp       Epoch  `json:"timestamp"`
+	Type            string `json:"type,omitempty"`
+	NormalizedEvent string `json:"normalizedEvent,omitempty"`
+	SourceIP        string `json:"srcIP,omitempty"`
+	SourcePort      Int    `json:"srcPort,omitempty"`
+	DestinationIP   string `json:"destIP,omitempty"`
+	DestinationPort Int    `json:"destPort,omitempty"`
+	Protocol        Int    `json:"protocol,omitempty"`
+	User            string `json:"user,omitempty"`
+	Sensor          string `json:"sensor,omitempty"`
+	LCE             LCE    `json:"lce,omitempty"`
+	Count           Int    `json:"eventCount,omitempty"`
+}
+
+// EventTool returns EventToolListData.
+func (EventData) EventTool() EventTool { return EventToolListData }
+
+// QueryEvents runs an event analysis with the tool of T and decodes the results as T, e.g.
+//
+//	types, _, err := tenable.QueryEvents[tenable.EventTypeSummary](ctx, client.Analysis, tenable.EventBody{EndOffset: 100})
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func QueryEvents[T EventResult](ctx context.Context, s *AnalysisService, body EventBody) (*Envelope[ResultSet[T]], *Response, error) {
+	var zero T
+	sourceType := body.SourceType
+	switch sourceType {
+	case "":
+		sourceType = SourceLCE
+	case SourceLCE:
+	case SourceArchive:
+		if body.LCEID == 0 || body.View == "" {
+			return nil, nil, fmt.Errorf("tenable: the %s source type requires an LCEID and a view", SourceArchive)
+		}
+	default:
+		return nil, nil, fmt.Errorf("tenable: invalid event sourceType %q", body.SourceType)
+	}
+	req := newTypedBody(AnalysisEvent, sourceType, string(zero.EventTool()), body.StartOffset, body.EndOffset, body.Filters)
+	if sourceType == SourceArchive {
+		req.LCEID, req.View = body.LCEID, body.View
+	}
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", req)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// testTypedAnalysis serves response for /rest/analysis and passes the decoded request to check.
+func testTypedAnalysis(t *testing.T, response string, check func(body map[string]interface{})) {
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		check(body)
+		w.Write([]byte(`{"type":"regular","response":` + response + `,"error_code":0,"error_msg":"","warnings":[],"timestamp":1553525692}`))
+	})
+}
+
+func TestQueryEvents(t *testing.T) {
+	setup()
+	defer teardown()
+	testTypedAnalysis(t, `{"totalRecords":"2","startOffset":"0","endOffset":"50","results":[{"type":"login","count":"120"},{"type":"error","count":"7"}]}`,
+		func(body map[string]interface{}) {
+			query := body["query"].(map[string]interface{})
+			if body["type"] != "event" || body["sourceType"] != "lce" || query["tool"] != "sumtype" || query["type"] != "event" {
+				t.Errorf("Unexpected body %v", body)
+			}
+			if filters, ok := query["filters"].([]interface{}); !ok || len(filters) != 0 {
+				t.Errorf("Expected empty filters, got %v", query["filters"])
+			}
+		})
+
+	types, resp, err := QueryEvents[EventTypeSummary](context.Background(), testClient.Analysis, EventBody{EndOffset: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := types.Response.Results; len(r) != 2 || r[0].Type != "login" || r[0].Count != 120 {
+		t.Errorf("Unexpected results %+v", r)
+	}
+	if resp.Total != 2 {
+		t.Errorf("Total: %d, want 2", resp.Total)
+	}
+
+	if _, _, err := QueryEvents[SyslogEvent](context.Background(), testClient.Analysis, EventBody{SourceType: SourceArchive}); err == nil {
+		t.Error("Expected an error for an archive query without LCE and view")
+	}
+	if _, _, err := QueryEvents[SyslogEvent](context.Background(), testClient.Analysis, EventBody{SourceType: SourceCumulative}); err == nil {
+		t.Error("Expected an error for a vulnerability source type")
+	}
+}
+
+func TestQueryEvents_Archive(t *testing.T) {
+	setup()
+	defer teardown()
+	testTypedAnalysis(t, `{"totalRecords":"1","results":[{"timestamp":"1553525692","message":"sshd[42]: Accepted publickey","lce":{"id":"1","name":"lce1"}}]}`,
+		func(body map[string]interface{}) {
+			if body["sourceType"] != "archive" || body["lceID"] != "3" || body["view"] != "2019-03" {
+				t.Errorf("Unexpected body %v", body)
+			}
+		})
+
+	logs, _, err := QueryEvents[SyslogEvent](context.Background(), testClient.Analysis, EventBody{SourceType: SourceArchive, LCEID: 3, View: "2019-03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := logs.Response.Results; len(r) != 1 || r[0].LCE.Name != "lce1" || r[0].Timestamp.Time().Unix() != 1553525692 {
+		t.Errorf("Unexpected results %+v", r)
+	}
+}
@@ -9,7 +9,6 @@ package main
 import (
 	"crypto/tls"
 	"fmt"
-	"net/http"
 	"os"
 
 	tenable "github.com/IBM/go-tenable"
@@ -23,17 +22,14 @@ func main() {
 		fmt.Printf("Missing env vars. Requred env vars SC05_URL,SC05_ACCESS_KEY and SC05_SECRET_KEY\n")
 	}
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	t := tenable.APIKeyAuthTransport{Transport: transport,
-		APIKey:    apiKey,
-		APISecret: apiSecret,
-	}
-
-	c, err := tenable.NewClient(t.Client(), apiURL)
+	c, err := tenable.New(apiURL,
+		tenable.WithAPIKey(apiKey, apiSecret),
+		tenable.WithTLSConfig(&tls.Config{InsecureSkipVerify: true}),
+		tenable.WithRetryPolicy(tenable.DefaultRetryPolicy()),
+	)
 	if err != nil {
 		fmt.Println(err.Error())
+		return
 	}
 	//"14272,11219,22964"
 	/*
@@ -69,7 +65,11 @@ func main() {
 			"type":"vuln"
 		}
 	*/
-	f := tenable.AnalysisFilter{}
+	f, err := tenable.PluginIDFilter(tenable.OpEqual, 14272, 11219, 22964)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
 	q := tenable.AnalysisQuery{}
 	b := tenable.AnalysisBody{}
 	q.Name = ""
@@ -85,12 +85,6 @@ func main() {
 	q.StartOffset = 0
 	q.EndOffset = 2
 
-	f.ID = "pluginID"
-	f.FilterName = "pluginID"
-	f.Operator = "="
-	f.Type = "vuln"
-	f.IsPredefined = true
-	f.Value = "14272,11219,22964"
 	q.Filters = []tenable.AnalysisFilter{f}
 	q.VulnTool = "listvuln"
 
@@ -0,0 +1,450 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFilter is wrapped by all errors of the filter constructors.
+var ErrInvalidFilter = errors.New("tenable: invalid filter")
+
+// FilterOperator is the operator of an AnalysisFilter.
+type FilterOperator string
+
+// Operators of analysis filters. Each filter only accepts some of them.
+const (
+	OpEqual          FilterOperator = "="
+	OpNotEqual       FilterOperator = "!="
+	OpGreaterOrEqual FilterOperator = ">="
+	OpLessOrEqual    FilterOperator = "<="
+	OpContains       FilterOperator = "~="
+)
+
+// SeverityLevel is the severity of a finding as used by the severity filter.
+type SeverityLevel int
+
+// Severity levels of Tenable.sc.
+const (
+	SeverityInfo SeverityLevel = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = [...]string{"info", "low", "medium", "high", "critical"}
+
+// String returns the lower case name of the severity, e.g. "high".
+func (l SeverityLevel) String() string {
+	if l < SeverityInfo || l > SeverityCritical {
+		return strconv.Itoa(int(l))
+	}
+	return severityNames[l]
+}
+
+// ParseSeverityLevel parses a severity name ("info" ... "critical") or number (0 ... 4).
+func ParseSeverityLevel(s string) (SeverityLevel, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range severityNames {
+		if s == name || s == strconv.Itoa(i) {
+			return SeverityLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown severity %q", ErrInvalidFilter, s)
+}
+
+// filterKind describes how the value of a filter is encoded.
+type filterKind int
+
+const (
+	// comma separated numbers, e.g. "19506,11219"
+	kindNumbers filterKind = iota
+	// comma separated severity levels, e.g. "3,4"
+	kindSeverity
+	// comma separated strings, e.g. IPs or CVE IDs
+	kindStrings
+	// list of objects with an id, e.g. [{"id":"1"}]
+	kindObjects
+	// range of days, e.g. "0:30"
+	kindDays
+	// "true" or "false"
+	kindBool
+	// range of scores, e.g. "7.0-10.0"
+	kindScore
+	// free text
+	kindText
+)
+
+// filterSpec describes a documented analysis filter.
+type filterSpec struct {
+	kind      filterKind
+	operators []FilterOperator
+}
+
+var (
+	eqOps    = []FilterOperator{OpEqual}
+	eqNeOps  = []FilterOperator{OpEqual, OpNotEqual}
+	cmpOps   = []FilterOperator{OpEqual, OpNotEqual, OpGreaterOrEqual, OpLessOrEqual}
+	textOps  = []FilterOperator{OpEqual, OpNotEqual, OpContains}
+	matchOps = []FilterOperator{OpEqual, OpContains}
+)
+
+// filterSpecs lists the predefined filters of vulnerability analysis and their valid operators.
+var filterSpecs = map[string]filterSpec{
+	"acceptRisk":        {kindBool, eqOps},
+	"assetID":           {kindObjects, eqNeOps},
+	"cpe":               {kindText, textOps},
+	"cveID":             {kindStrings, eqNeOps},
+	"cvssV3BaseScore":   {kindScore, eqOps},
+	"dnsName":           {kindStrings, textOps},
+	"exploitAvailable":  {kindBool, eqOps},
+	"exploitFrameworks": {kindText, textOps},
+	"family":            {kindObjects, eqNeOps},
+	"firstSeen":         {kindDays, eqOps},
+	"ip":                {kindStrings, eqNeOps},
+	"lastSeen":          {kindDays, eqOps},
+	"patchPublished":    {kindDays, eqOps},
+	"pluginID":          {kindNumbers, cmpOps},
+	"pluginModified":    {kindDays, eqOps},
+	"pluginName":        {kindText, matchOps},
+	"pluginPublished":   {kindDays, eqOps},
+	"pluginText":        {kindText, matchOps},
+	"pluginType":        {kindText, eqOps},
+	"port":              {kindNumbers, cmpOps},
+	"protocol":          {kindNumbers, eqNeOps},
+	"recastRisk":        {kindBool, eqOps},
+	"repository":        {kindObjects, eqNeOps},
+	"severity":          {kindSeverity, eqNeOps},
+	"vprScore":          {kindScore, eqOps},
+	"vulnPublished":     {kindDays, eqOps},
+	"xref":              {kindText, textOps},
+}
+
+// FilterNames returns the names of all predefined filters supported by NewFilter, sorted.
+func FilterNames() []string {
+	names := make([]string, 0, len(filterSpecs))
+	for name := range filterSpecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FilterOperators returns the operators valid for the predefined filter name.
+func FilterOperators(name string) []FilterOperator {
+	return append([]FilterOperator(nil), filterSpecs[name].operators...)
+}
+
+// NewFilter returns a predefined vulnerability filter with an already encoded value.
+// It fails for unknown filter names and operators the filter does not support.
+// Prefer the typed constructors like SeverityFilter, which also encode the value.
+func NewFilter(name string, op FilterOperator, value interface{}) (AnalysisFilter, error) {
+	spec, ok := filterSpecs[name]
+	if !ok {
+		return AnalysisFilter{}, fmt.Errorf("%w: unknown filter %q", ErrInvalidFilter, name)
+	}
+	if !containsOperator(spec.operators, op) {
+		return AnalysisFilter{}, fmt.Errorf("%w: operator %q is not valid for %s, use one of %v", ErrInvalidFilter, op, name, spec.operators)
+	}
+	return AnalysisFilter{
+		ID:           name,
+		FilterName:   name,
+		Operator:     string(op),
+		Type:         "vuln",
+		IsPredefined: true,
+		Value:        value,
+	}, nil
+}
+
+func containsOperator(ops []FilterOperator, op FilterOperator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// DayRange is a range of days before today as used by lastSeen, firstSeen and the published date filters.
+// To < 0 means without upper bound ("30:all").
+type DayRange struct {
+	From int
+	To   int
+}
+
+// WithinDays returns the range of the last n days, e.g. "0:30".
+func WithinDays(n int) DayRange {
+	return DayRange{From: 0, To: n}
+}
+
+// OlderThanDays returns the range of everything older than n days, e.g. "30:all".
+func OlderThanDays(n int) DayRange {
+	return DayRange{From: n, To: -1}
+}
+
+// String returns the filter value of r, e.g. "0:30" or "30:all".
+func (r DayRange) String() string {
+	if r.To < 0 {
+		return fmt.Sprintf("%d:all", r.From)
+	}
+	return fmt.Sprintf("%d:%d", r.From, r.To)
+}
+
+// ParseDayRange parses a day range value like "0:30" or "30:all".
+func ParseDayRange(s string) (DayRange, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return DayRange{}, fmt.Errorf("%w: invalid day range %q", ErrInvalidFilter, s)
+	}
+	var r DayRange
+	var err error
+	if r.From, err = strconv.Atoi(from); err != nil {
+		return DayRange{}, fmt.Errorf("%w: invalid day range %q", ErrInvalidFilter, s)
+	}
+	if to == "all" {
+		r.To = -1
+	} else if r.To, err = strconv.Atoi(to); err != nil {
+		return DayRange{}, fmt.Errorf("%w: invalid day range %q", ErrInvalidFilter, s)
+	}
+	return r, r.validate()
+}
+
+func (r DayRange) validate() error {
+	if r.From < 0 || (r.To >= 0 && r.To < r.From) {
+		return fmt.Errorf("%w: invalid day range %s", ErrInvalidFilter, r)
+	}
+	return nil
+}
+
+// PluginIDFilter matches the given plugin IDs. With >= and <= exactly one ID is allowed.
+func PluginIDFilter(op FilterOperator, ids ...int) (AnalysisFilter, error) {
+	return numbersFilter("pluginID", op, ids, 0, 1<<31-1)
+}
+
+// PortFilter matches the given ports. With >= and <= exactly one port is allowed.
+func PortFilter(op FilterOperator, ports ...int) (AnalysisFilter, error) {
+	return numbersFilter("port", op, ports, 0, 65535)
+}
+
+// ProtocolFilter matches the given IP protocol numbers, e.g. 6 for TCP and 17 for UDP.
+func ProtocolFilter(op FilterOperator, protocols ...int) (AnalysisFilter, error) {
+	return numbersFilter("protocol", op, protocols, 0, 255)
+}
+
+func numbersFilter(name string, op FilterOperator, numbers []int, min, max int) (AnalysisFilter, error) {
+	if len(numbers) == 0 {
+		return AnalysisFilter{}, fmt.Errorf("%w: %s needs at least one value", ErrInvalidFilter, name)
+	}
+	if (op == OpGreaterOrEqual || op == OpLessOrEqual) && len(numbers) != 1 {
+		return AnalysisFilter{}, fmt.Errorf("%w: %s %s needs exactly one value", ErrInvalidFilter, name, op)
+	}
+	values := make([]string, len(numbers))
+	for i, n := range numbers {
+		if n < min || n > max {
+			return AnalysisFilter{}, fmt.Errorf("%w: %s value %d out of range [%d, %d]", ErrInvalidFilter, name, n, min, max)
+		}
+		values[i] = strconv.Itoa(n)
+	}
+	return NewFilter(name, op, strings.Join(values, ","))
+}
+
+// SeverityFilter matches findings of the given severities, e.g. SeverityFilter(OpEqual, SeverityHigh, SeverityCritical).
+func SeverityFilter(op FilterOperator, severities ...SeverityLevel) (AnalysisFilter, error) {
+	if len(severities) == 0 {
+		return AnalysisFilter{}, fmt.Errorf("%w: severity needs at least one value", ErrInvalidFilter)
+	}
+	values := make([]string, len(severities))
+	for i, s := range severities {
+		if s < SeverityInfo || s > SeverityCritical {
+			return AnalysisFilter{}, fmt.Errorf("%w: unknown severity %d", ErrInvalidFilter, s)
+		}
+		values[i] = strconv.Itoa(int(s))
+	}
+	return NewFilter("severity", op, strings.Join(values, ","))
+}
+
+// SeverityAtLeastFilter matches findings of severity min or higher.
+func SeverityAtLeastFilter(min SeverityLevel) (AnalysisFilter, error) {
+	var levels []SeverityLevel
+	for l := min; l <= SeverityCritical; l++ {
+		levels = append(levels, l)
+	}
+	return SeverityFilter(OpEqual, levels...)
+}
+
+// IPFilter matches hosts by addresses, CIDR blocks or ranges like "10.0.0.1-10.0.0.9".
+func IPFilter(op FilterOperator, addresses ...string) (AnalysisFilter, error) {
+	return stringsFilter("ip", op, addresses)
+}
+
+// DNSNameFilter matches hosts by DNS name.
+func DNSNameFilter(op FilterOperator, names ...string) (AnalysisFilter, error) {
+	return stringsFilter("dnsName", op, names)
+}
+
+// CVEIDFilter matches findings by CVE IDs, e.g. "CVE-2021-44228".
+func CVEIDFilter(op FilterOperator, cves ...string) (AnalysisFilter, error) {
+	for _, cve := range cves {
+		if !strings.HasPrefix(strings.ToUpper(cve), "CVE-") {
+			return AnalysisFilter{}, fmt.Errorf("%w: invalid CVE ID %q", ErrInvalidFilter, cve)
+		}
+	}
+	return stringsFilter("cveID", op, cves)
+}
+
+func stringsFilter(name string, op FilterOperator, values []string) (AnalysisFilter, error) {
+	if len(values) == 0 {
+		return AnalysisFilter{}, fmt.Errorf("%w: %s needs at least one value", ErrInvalidFilter, name)
+	}
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" || strings.Contains(v, ",") {
+			return AnalysisFilter{}, fmt.Errorf("%w: invalid %s value %q", ErrInvalidFilter, name, v)
+		}
+	}
+	return NewFilter(name, op, strings.Join(values, ","))
+}
+
+// RepositoryFilter matches findings of the given repositories.
+func RepositoryFilter(op FilterOperator, ids ...ID) (AnalysisFilter, error) {
+	return objectsFilter("repository", op, ids)
+}
+
+// FamilyFilter matches findings of the given plugin families.
+func FamilyFilter(op FilterOperator, ids ...ID) (AnalysisFilter, error) {
+	return objectsFilter("family", op, ids)
+}
+
+// AssetIDFilter matches findings of hosts in the given assets.
+func AssetIDFilter(op FilterOperator, ids ...ID) (AnalysisFilter, error) {
+	return objectsFilter("assetID", op, ids)
+}
+
+// filterObject is an object referenced by id in a filter value.
+type filterObject struct {
+	ID ID `json:"id"`
+}
+
+func objectsFilter(name string, op FilterOperator, ids []ID) (AnalysisFilter, error) {
+	if len(ids) == 0 {
+		return AnalysisFilter{}, fmt.Errorf("%w: %s needs at least one value", ErrInvalidFilter, name)
+	}
+	objects := make([]filterObject, len(ids))
+	for i, id := range ids {
+		objects[i] = filterObject{ID: id}
+	}
+	return NewFilter(name, op, objects)
+}
+
+// LastSeenFilter matches findings last seen within r, e.g. LastSeenFilter(WithinDays(30)).
+func LastSeenFilter(r DayRange) (AnalysisFilter, error) {
+	return daysFilter("lastSeen", r)
+}
+
+// FirstSeenFilter matches findings first seen within r.
+func FirstSeenFilter(r DayRange) (AnalysisFilter, error) {
+	return daysFilter("firstSeen", r)
+}
+
+// PluginPublishedFilter matches findings of plugins published within r.
+func PluginPublishedFilter(r DayRange) (AnalysisFilter, error) {
+	return daysFilter("pluginPublished", r)
+}
+
+// PatchPublishedFilter matches findings whose patch was published within r.
+func PatchPublishedFilter(r DayRange) (AnalysisFilter, error) {
+	return daysFilter("patchPublished", r)
+}
+
+// VulnPublishedFilter matches findings whose vulnerability was published within r.
+func VulnPublishedFilter(r DayRange) (AnalysisFilter, error) {
+	return daysFilter("vulnPublished", r)
+}
+
+func daysFilter(name string, r DayRange) (AnalysisFilter, error) {
+	if err := r.validate(); err != nil {
+		return AnalysisFilter{}, err
+	}
+	return NewFilter(name, OpEqual, r.String())
+}
+
+// ExploitAvailableFilter matches findings with (or without) a known exploit.
+func ExploitAvailableFilter(available bool) (AnalysisFilter, error) {
+	return NewFilter("exploitAvailable", OpEqual, strconv.FormatBool(available))
+}
+
+// AcceptRiskFilter matches findings whose risk was (or was not) accepted.
+func AcceptRiskFilter(accepted bool) (AnalysisFilter, error) {
+	return NewFilter("acceptRisk", OpEqual, strconv.FormatBool(accepted))
+}
+
+// RecastRiskFilter matches findings whose severity was (or was not) recast.
+func RecastRiskFilter(recast bool) (AnalysisFilter, error) {
+	return NewFilter("recastRisk", OpEqual, strconv.FormatBool(recast))
+}
+
+// VPRScoreFilter matches findings with a VPR score between min and max (0.0 to 10.0).
+func VPRScoreFilter(min, max float64) (AnalysisFilter, error) {
+	return scoreFilter("vprScore", min, max)
+}
+
+// CVSSV3BaseScoreFilter matches findings with a CVSS v3 base score between min and max (0.0 to 10.0).
+func CVSSV3BaseScoreFilter(min, max float64) (AnalysisFilter, error) {
+	return scoreFilter("cvssV3BaseScore", min, max)
+}
+
+func scoreFilter(name string, min, max float64) (AnalysisFilter, error) {
+	if min < 0 || max > 10 || min > max {
+		return AnalysisFilter{}, fmt.Errorf("%w: invalid %s range %.1f-%.1f", ErrInvalidFilter, name, min, max)
+	}
+	return NewFilter(name, OpEqual, fmt.Sprintf("%.1f-%.1f", min, max))
+}
+
+// PluginNameFilter matches findings by plugin name, OpContains matches a part of the name.
+func PluginNameFilter(op FilterOperator, name string) (AnalysisFilter, error) {
+	return textFilter("pluginName", op, name)
+}
+
+// PluginTextFilter matches findings by plugin output, OpContains matches a part of the output.
+func PluginTextFilter(op FilterOperator, text string) (AnalysisFilter, error) {
+	return textFilter("pluginText", op, text)
+}
+
+// PluginTypeFilter matches findings by plugin type: "active", "passive", "lce" or "compliance".
+func PluginTypeFilter(pluginType string) (AnalysisFilter, error) {
+	switch pluginType {
+	case "active", "passive", "lce", "compliance":
+		return textFilter("pluginType", OpEqual, pluginType)
+	}
+	return AnalysisFilter{}, fmt.Errorf("%w: unknown plugin type %q", ErrInvalidFilter, pluginType)
+}
+
+// ExploitFrameworksFilter matches findings by the exploit frameworks, e.g. "Metasploit".
+func ExploitFrameworksFilter(op FilterOperator, text string) (AnalysisFilter, error) {
+	return textFilter("exploitFrameworks", op, text)
+}
+
+// CPEFilter matches findings by CPE.
+func CPEFilter(op FilterOperator, cpe string) (AnalysisFilter, error) {
+	return textFilter("cpe", op, cpe)
+}
+
+// XrefFilter matches findings by cross reference, e.g. "IAVA:2021-A-0573".
+func XrefFilter(op FilterOperator, xref string) (AnalysisFilter, error) {
+	return textFilter("xref", op, xref)
+}
+
+func textFilter(name string, op FilterOperator, text string) (AnalysisFilter, error) {
+	if strings.TrimSpace(text) == "" {
+		return AnalysisFilter{}, fmt.Errorf("%w: %s needs a value", ErrInvalidFilter, name)
+	}
+	return NewFilter(name, op, text)
+}
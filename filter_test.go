@@ -0,0 +1,96 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFilterConstructors(t *testing.T) {
+	must := func(f AnalysisFilter, err error) AnalysisFilter {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	tests := []struct {
+		filter AnalysisFilter
+		want   string
+	}{
+		{must(PluginIDFilter(OpEqual, 14272, 11219)), `{"id":"pluginID","filterName":"pluginID","operator":"=","type":"vuln","isPredefined":true,"value":"14272,11219"}`},
+		{must(PluginIDFilter(OpGreaterOrEqual, 100000)), `{"id":"pluginID","filterName":"pluginID","operator":"\u003e=","type":"vuln","isPredefined":true,"value":"100000"}`},
+		{must(SeverityFilter(OpEqual, SeverityHigh, SeverityCritical)), `{"id":"severity","filterName":"severity","operator":"=","type":"vuln","isPredefined":true,"value":"3,4"}`},
+		{must(SeverityAtLeastFilter(SeverityMedium)), `{"id":"severity","filterName":"severity","operator":"=","type":"vuln","isPredefined":true,"value":"2,3,4"}`},
+		{must(RepositoryFilter(OpEqual, 1, 7)), `{"id":"repository","filterName":"repository","operator":"=","type":"vuln","isPredefined":true,"value":[{"id":"1"},{"id":"7"}]}`},
+		{must(LastSeenFilter(WithinDays(30))), `{"id":"lastSeen","filterName":"lastSeen","operator":"=","type":"vuln","isPredefined":true,"value":"0:30"}`},
+		{must(FirstSeenFilter(OlderThanDays(30))), `{"id":"firstSeen","filterName":"firstSeen","operator":"=","type":"vuln","isPredefined":true,"value":"30:all"}`},
+		{must(ExploitAvailableFilter(true)), `{"id":"exploitAvailable","filterName":"exploitAvailable","operator":"=","type":"vuln","isPredefined":true,"value":"true"}`},
+		{must(VPRScoreFilter(7, 10)), `{"id":"vprScore","filterName":"vprScore","operator":"=","type":"vuln","isPredefined":true,"value":"7.0-10.0"}`},
+		{must(PluginNameFilter(OpContains, "OpenSSL")), `{"id":"pluginName","filterName":"pluginName","operator":"~=","type":"vuln","isPredefined":true,"value":"OpenSSL"}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Got %s\nwant %s", got, tt.want)
+		}
+	}
+}
+
+func TestFilterValidation(t *testing.T) {
+	tests := map[string]func() (AnalysisFilter, error){
+		"unknown filter":     func() (AnalysisFilter, error) { return NewFilter("bogus", OpEqual, "1") },
+		"severity >=":        func() (AnalysisFilter, error) { return SeverityFilter(OpGreaterOrEqual, SeverityHigh) },
+		"severity range":     func() (AnalysisFilter, error) { return SeverityFilter(OpEqual, 5) },
+		"no plugin IDs":      func() (AnalysisFilter, error) { return PluginIDFilter(OpEqual) },
+		"plugin ID list <=":  func() (AnalysisFilter, error) { return PluginIDFilter(OpLessOrEqual, 1, 2) },
+		"port range":         func() (AnalysisFilter, error) { return PortFilter(OpEqual, 70000) },
+		"ip contains":        func() (AnalysisFilter, error) { return IPFilter(OpContains, "10.0.0.1") },
+		"cve":                func() (AnalysisFilter, error) { return CVEIDFilter(OpEqual, "2021-44228") },
+		"day range":          func() (AnalysisFilter, error) { return LastSeenFilter(DayRange{From: 30, To: 7}) },
+		"vpr range":          func() (AnalysisFilter, error) { return VPRScoreFilter(5, 11) },
+		"empty plugin name":  func() (AnalysisFilter, error) { return PluginNameFilter(OpEqual, " ") },
+		"unknown pluginType": func() (AnalysisFilter, error) { return PluginTypeFilter("remote") },
+	}
+	for name, fn := range tests {
+		if _, err := fn(); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", name, err)
+		}
+	}
+}
+
+func TestParseDayRange(t *testing.T) {
+	for _, s := range []string{"0:30", "30:all"} {
+		r, err := ParseDayRange(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.String() != s {
+			t.Errorf("Got %s, want %s", r, s)
+		}
+	}
+	if _, err := ParseDayRange("30"); err == nil {
+		t.Error("Expected an error for a missing colon")
+	}
+}
+
+func TestParseSeverityLevel(t *testing.T) {
+	for in, want := range map[string]SeverityLevel{"high": SeverityHigh, "Critical": SeverityCritical, "0": SeverityInfo} {
+		got, err := ParseSeverityLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSeverityLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseSeverityLevel("severe"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+}
@@ -1,7 +1,9 @@
 module github.com/IBM/go-tenable
 
-go 1.18
+go 1.23
 
 require github.com/google/go-querystring v1.1.0
 
 require github.com/pkg/errors v0.9.1
+
+require github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
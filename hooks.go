@@ -0,0 +1,190 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxLoggedBody is the number of body bytes included in debug logs.
+const maxLoggedBody = 2048
+
+// RequestInfo describes a single attempt of a request sent to Tenable.
+// It is passed to the BeforeRequest and AfterResponse hooks.
+type RequestInfo struct {
+	// Method and Endpoint of the request, e.g. "POST" and "/rest/analysis"
+	Method   string
+	Endpoint string
+
+	// Header of the request with all credentials redacted
+	Header http.Header
+
+	// Retry is the number of the retry, zero for the first attempt
+	Retry int
+
+	// StatusCode of the response, zero before the request is sent or if it failed
+	StatusCode int
+
+	// Duration of the attempt including reading the response body, zero before the request is sent
+	Duration time.Duration
+
+	// BytesSent is the size of the request body, BytesReceived the number of response body bytes read
+	// before the attempt was reported
+	BytesSent     int64
+	BytesReceived int64
+
+	// Err is the transport error of the attempt, if any
+	Err error
+}
+
+// BeforeRequestHook is called before each attempt of a request is sent.
+type BeforeRequestHook func(ctx context.Context, info *RequestInfo)
+
+// AfterResponseHook is called after each attempt of a request failed, or once its response body was closed.
+// Client.Do reports the attempt at the latest when it returns, so a response body that Do hands to
+// its caller unread is reported before the caller reads it. The streaming AnalysisService methods,
+// e.g. Stream and Download, report the attempt once their body is read and closed.
+type AfterResponseHook func(ctx context.Context, info *RequestInfo)
+
+// roundTrip sends a single attempt of req, waiting for the rate limit and
+// reporting the attempt to the configured logger and hooks.
+func (c *Client) roundTrip(req *http.Request, retry int) (*http.Response, error) {
+	ctx := req.Context()
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.logger == nil && len(c.beforeRequest) == 0 && len(c.afterResponse) == 0 {
+		return c.client.Do(req)
+	}
+
+	info := &RequestInfo{
+		Method:    req.Method,
+		Endpoint:  req.URL.Path,
+		Header:    RedactHeader(req.Header),
+		Retry:     retry,
+		BytesSent: req.ContentLength,
+	}
+	for _, hook := range c.beforeRequest {
+		hook(ctx, info)
+	}
+
+	debug := c.logger != nil && c.logger.Enabled(ctx, slog.LevelDebug)
+	var requestBody []byte
+	if debug && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(body, maxLoggedBody))
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		info.Duration = time.Since(start)
+		info.Err = err
+		c.finishAttempt(ctx, info, requestBody, nil)
+		return nil, err
+	}
+
+	info.StatusCode = resp.StatusCode
+	body := &observedBody{ReadCloser: resp.Body}
+	if debug {
+		body.capture = new(bytes.Buffer)
+	}
+	body.done = func() {
+		info.Duration = time.Since(start)
+		info.BytesReceived = body.n
+		var responseBody []byte
+		if body.capture != nil {
+			responseBody = body.capture.Bytes()
+		}
+		c.finishAttempt(ctx, info, requestBody, responseBody)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// finishAttempt logs the attempt and calls the AfterResponse hooks.
+func (c *Client) finishAttempt(ctx context.Context, info *RequestInfo, requestBody, responseBody []byte) {
+	if c.logger != nil {
+		attrs := []slog.Attr{
+			slog.String("method", info.Method),
+			slog.String("endpoint", info.Endpoint),
+			slog.Int("status", info.StatusCode),
+			slog.Duration("duration", info.Duration),
+			slog.Int("retry", info.Retry),
+			slog.Int64("bytes_sent", info.BytesSent),
+			slog.Int64("bytes_received", info.BytesReceived),
+		}
+		if c.logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs,
+				slog.Any("request_header", info.Header),
+				slog.String("request_body", string(RedactBody(requestBody))),
+				slog.String("response_body", string(RedactBody(responseBody))),
+			)
+		}
+		switch {
+		case info.Err != nil:
+			attrs = append(attrs, slog.String("error", info.Err.Error()))
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "tenable request failed", attrs...)
+		case info.StatusCode < 200 || info.StatusCode > 299:
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "tenable request failed", attrs...)
+		default:
+			c.logger.LogAttrs(ctx, slog.LevelDebug, "tenable request", attrs...)
+		}
+	}
+	for _, hook := range c.afterResponse {
+		hook(ctx, info)
+	}
+}
+
+// observedBody counts the bytes read from a response body, optionally captures the
+// beginning of it, and reports the attempt once the body is closed.
+type observedBody struct {
+	io.ReadCloser
+	n       int64
+	capture *bytes.Buffer
+	done    func()
+	once    sync.Once
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if b.capture != nil && b.capture.Len() < maxLoggedBody {
+		rest := maxLoggedBody - b.capture.Len()
+		if rest > n {
+			rest = n
+		}
+		b.capture.Write(p[:rest])
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// finishBody reports the attempt of resp if its body was not closed yet.
+func finishBody(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	if b, ok := resp.Body.(*observedBody); ok {
+		b.once.Do(b.done)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_LoggerRedactsCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	attempts := 0
+	mux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "TNS_SESSIONID", Value: "cookie-secret"})
+		fmt.Fprint(w, `{"type":"regular","response":{"token":987654},"error_code":0}`)
+	})
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0}`)
+	})
+
+	var logs bytes.Buffer
+	var infos []RequestInfo
+	c, err := New(server.URL,
+		WithSessionCredentials("admin", "pa55word"),
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		WithAfterResponse(func(ctx context.Context, info *RequestInfo) { infos = append(infos, *info) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CurrentUser.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := logs.String()
+	for _, secret := range []string{"pa55word", "987654", "cookie-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Log contains credential %q:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `\"username\":\"admin\"`) {
+		t.Errorf("Expected the request body to be logged:\n%s", out)
+	}
+
+	if len(infos) != 3 {
+		t.Fatalf("Expected 3 attempts (login, failure, retry), got %d", len(infos))
+	}
+	login, failed, retried := infos[0], infos[1], infos[2]
+	if login.Endpoint != "/rest/token" || login.BytesSent == 0 || login.BytesReceived == 0 {
+		t.Errorf("Unexpected login info %+v", login)
+	}
+	if failed.StatusCode != http.StatusServiceUnavailable || failed.Retry != 0 {
+		t.Errorf("Unexpected failed attempt %+v", failed)
+	}
+	if retried.StatusCode != http.StatusOK || retried.Retry != 1 || retried.Duration <= 0 {
+		t.Errorf("Unexpected retried attempt %+v", retried)
+	}
+	if got := retried.Header.Get("X-SecurityCenter"); got != "REDACTED" {
+		t.Errorf("X-SecurityCenter: %q, want REDACTED", got)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Apikey", "accesskey=foo; secretkey=bar;")
+	h.Set("Content-Type", "application/json")
+	got := RedactHeader(h)
+	if got.Get("X-Apikey") != "REDACTED" || got.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected header %v", got)
+	}
+	if h.Get("X-Apikey") == "REDACTED" {
+		t.Error("Expected the original header to be unchanged")
+	}
+
+	body := `{"username":"admin","password":"p\"w","token":123,"secretKey":"abc","name":"x"}`
+	want := `{"username":"admin","password":"REDACTED","token":0,"secretKey":"REDACTED","name":"x"}`
+	if got := string(RedactBody([]byte(body))); got != want {
+		t.Errorf("RedactBody: %s, want %s", got, want)
+	}
+	if got := string(RedactBody([]byte(`{"password":"trunc`))); got != `{"password":"REDACTED"` {
+		t.Errorf("RedactBody of truncated body: %s", got)
+	}
+}
+
+func TestClient_LoggerWarnsOnErrorStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	var logs bytes.Buffer
+	c, err := New(server.URL,
+		WithAPIKey("access", "secret"),
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CurrentUser.Get(); err == nil {
+		t.Fatal("Expected an error for a 404 response")
+	}
+	if out := logs.String(); !strings.Contains(out, `"level":"WARN"`) || !strings.Contains(out, `"status":404`) {
+		t.Errorf("Expected a warning for the 404 response:\n%s", out)
+	}
+}
+
+func TestClient_AfterResponseWithoutClose(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1"},"error_code":0}`)
+	})
+
+	var infos []RequestInfo
+	c, err := New(server.URL,
+		WithAPIKey("access", "secret"),
+		WithAfterResponse(func(ctx context.Context, info *RequestInfo) { infos = append(infos, *info) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := c.NewRequest("GET", "/rest/currentUser", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if len(infos) != 1 || infos[0].StatusCode != http.StatusOK {
+		t.Errorf("Expected the attempt to be reported when Do returns, got %+v", infos)
+	}
+}
+
+// failingReplay answers the first request to /rest/currentUser with 401 and fails its replay.
+type failingReplay struct {
+	next  http.RoundTripper
+	calls int
+}
+
+func (f *failingReplay) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/rest/currentUser" {
+		f.calls++
+		if f.calls > 1 {
+			return nil, errors.New("connection reset")
+		}
+	}
+	return f.next.RoundTrip(req)
+}
+
+func TestClient_ReplayTransportError(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"regular","response":{"token":1001},"error_code":0}`)
+	})
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type":"regular","response":"","error_code":74,"error_msg":"Invalid token"}`)
+	})
+
+	var infos []RequestInfo
+	c, err := New(server.URL,
+		WithHTTPClient(&http.Client{Transport: &failingReplay{next: http.DefaultTransport}}),
+		WithSessionCredentials("admin", "pa55word"),
+		WithAfterResponse(func(ctx context.Context, info *RequestInfo) { infos = append(infos, *info) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CurrentUser.Get(); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Fatalf("Expected the transport error of the replay, got %v", err)
+	}
+	// login, expired session, login, failed replay
+	if len(infos) != 4 || infos[1].StatusCode != http.StatusUnauthorized || infos[2].Endpoint != "/rest/token" || infos[3].Err == nil {
+		t.Errorf("Unexpected attempts %+v", infos)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+)
+
+// MobileTool is the analysis tool of a mobile query.
+type MobileTool string
+
+// Analysis tools of mobile queries.
+const (
+	MobileToolListVuln    MobileTool = "listvuln"
+	MobileToolSumDeviceID MobileTool = "sumdeviceid"
+	MobileToolSumModel    MobileTool = "summodel"
+)
+
+// MobileResult is implemented by the result types of the mobile tools.
+type MobileResult interface {
+	MobileTool() MobileTool
+}
+
+// MobileBody is a mobile device analysis query. The tool is set from the result type.
+type MobileBody struct {
+	StartOffset int64
+	EndOffset   int64
+	Filters     []AnalysisFilter
+}
+
+// MobileVulnerability is a result of the listvuln tool of mobile queries, a finding on a device.
+type MobileVulnerability struct {
+	PluginID     ID         `json:"pluginID"`
+	Name         string     `json:"name,omitempty"`
+	Severity     Severity   `json:"severity,omitempty"`
+	Identifier   string     `json:"identifier,omitempty"`
+	Model        string     `json:"model,omitempty"`
+	OSVersion    string     `json:"osVersion,omitempty"`
+	SerialNumber string     `json:"serialNumber,omitempty"`
+	User         string     `json:"user,omitempty"`
+	MDMType      string     `json:"mdmType,omitempty"`
+	LastSeen     Epoch      `json:"lastSeen,omitempty"`
+	Repository   Repository `json:"repository,omitempty"`
+}
+
+// MobileTool returns MobileToolListVuln.
+func (MobileVulnerability) MobileTool() MobileTool { return MobileToolListVuln }
+
+// MobileDeviceSummary is a result of the sumdeviceid tool, a device with its severity counts.
+type MobileDeviceSummary struct {
+	Identifier   string     `json:"identifier"`
+	Model        string     `json:"model,omitempty"`
+	OSVersion    string     `json:"osVersion,omitempty"`
+	SerialNumber string     `json:"serialNumber,omitempty"`
+	User         string     `json:"user,omitempty"`
+	LastSeen     Epoch      `json:"lastSeen,omitempty"`
+	Repository   Repository `json:"repository,omitempty"`
+	SeverityCounts
+}
+
+// MobileTool returns MobileToolSumDeviceID.
+func (MobileDeviceSummary) MobileTool() MobileTool { return MobileToolSumDeviceID }
+
+// MobileModelSummary is a result of the summodel tool.
+type MobileModelSummary struct {
+	Model string `json:"model"`
+	SeverityCounts
+}
+
+// MobileTool returns MobileToolSumModel.
+func (MobileModelSummary) MobileTool() MobileTool { return MobileToolSumModel }
+
+// QueryMobile runs a mobile analysis with the tool of T and decodes the results as T.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func QueryMobile[T MobileResult](ctx context.Context, s *AnalysisService, body MobileBody) (*Envelope[ResultSet[T]], *Response, error) {
+	var zero T
+	req := newTypedBody(AnalysisMobile, "", string(zero.MobileTool()), body.StartOffset, body.EndOffset, body.Filters)
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", req)
+}
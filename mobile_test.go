@@ -0,0 +1,32 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryMobile(t *testing.T) {
+	setup()
+	defer teardown()
+	testTypedAnalysis(t, `{"totalRecords":"1","results":[{"identifier":"a1b2","model":"iPhone12,1","osVersion":"16.4","total":"3","severityHigh":"1"}]}`,
+		func(body map[string]interface{}) {
+			query := body["query"].(map[string]interface{})
+			if body["type"] != "mobile" || query["tool"] != "sumdeviceid" || body["sourceType"] != nil {
+				t.Errorf("Unexpected body %v", body)
+			}
+		})
+
+	devices, _, err := QueryMobile[MobileDeviceSummary](context.Background(), testClient.Analysis, MobileBody{EndOffset: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := devices.Response.Results; len(r) != 1 || r[0].Identifier != "a1b2" || r[0].Total != 3 || r[0].SeverityHigh != 1 {
+		t.Errorf("Unexpected results %+v", r)
+	}
+}
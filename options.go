@@ -0,0 +1,273 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Option configures a Client created with New.
+type Option func(*clientConfig) error
+
+// clientConfig collects the options passed to New.
+type clientConfig struct {
+	httpClient httpClient
+
+	// transport settings, only used if no httpClient is given
+	tlsConfig *tls.Config
+	rootCAs   *x509.CertPool
+	proxy     func(*http.Request) (*url.URL, error)
+	timeout   time.Duration
+
+	apiKey    string
+	apiSecret string
+	username  string
+	password  string
+
+	userAgent     string
+	retryPolicy   *RetryPolicy
+	limiter       *rateLimiter
+	logger        *slog.Logger
+	beforeRequest []BeforeRequestHook
+	afterResponse []AfterResponseHook
+}
+
+// New returns a new Tenable API client for baseURL configured by opts.
+// Without options it is equivalent to NewClient(nil, baseURL).
+//
+//	c, err := tenable.New("https://sc.example.com/",
+//		tenable.WithAPIKey(accessKey, secretKey),
+//		tenable.WithCAFile("/etc/ssl/tenable-ca.pem"),
+//		tenable.WithRetryPolicy(tenable.DefaultRetryPolicy()),
+//	)
+func New(baseURL string, opts ...Option) (*Client, error) {
+	cfg := &clientConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.apiKey != "" && cfg.username != "" {
+		return nil, errors.New("WithAPIKey and WithSessionCredentials cannot be combined")
+	}
+
+	httpClient, err := cfg.buildHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(httpClient, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.apiKey != "":
+		c.Authentication.apiKey = cfg.apiKey
+		c.Authentication.apiSecret = cfg.apiSecret
+		c.Authentication.authType = authTypeAPIKey
+	case cfg.username != "":
+		// the session is acquired with the first request
+		c.Authentication.username = cfg.username
+		c.Authentication.password = cfg.password
+		c.Authentication.authType = authTypeSession
+	}
+	c.userAgent = cfg.userAgent
+	c.retryPolicy = cfg.retryPolicy
+	c.limiter = cfg.limiter
+	c.logger = cfg.logger
+	c.beforeRequest = cfg.beforeRequest
+	c.afterResponse = cfg.afterResponse
+	return c, nil
+}
+
+// buildHTTPClient returns the configured httpClient or builds one from the transport options.
+func (cfg *clientConfig) buildHTTPClient() (httpClient, error) {
+	customTransport := cfg.tlsConfig != nil || cfg.rootCAs != nil || cfg.proxy != nil || cfg.timeout != 0
+	if cfg.httpClient != nil {
+		if customTransport {
+			return nil, errors.New("TLS, CA, proxy and timeout options cannot be combined with WithHTTPClient")
+		}
+		return cfg.httpClient, nil
+	}
+	if !customTransport {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.tlsConfig != nil {
+		transport.TLSClientConfig = cfg.tlsConfig.Clone()
+	}
+	if cfg.rootCAs != nil {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = cfg.rootCAs
+	}
+	if cfg.proxy != nil {
+		transport.Proxy = cfg.proxy
+	}
+	return &http.Client{Transport: transport, Timeout: cfg.timeout}, nil
+}
+
+// WithHTTPClient sets the HTTP client used to talk to Tenable, e.g. an APIKeyAuthTransport client.
+// It cannot be combined with the TLS, CA, proxy and timeout options.
+func WithHTTPClient(httpClient httpClient) Option {
+	return func(cfg *clientConfig) error {
+		cfg.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithAPIKey authenticates all requests with the access and secret key of an API key.
+// It cannot be combined with WithSessionCredentials.
+//
+// See https://docs.tenable.com/tenablesc/api_best_practices/Content/ScApiBestPractices/APIKeyAuthorization.htm
+func WithAPIKey(accessKey, secretKey string) Option {
+	return func(cfg *clientConfig) error {
+		if accessKey == "" || secretKey == "" {
+			return errors.New("access key and secret key must not be empty")
+		}
+		cfg.apiKey = accessKey
+		cfg.apiSecret = secretKey
+		return nil
+	}
+}
+
+// WithSessionCredentials authenticates with a session acquired from /rest/token for username and password.
+// The session is created with the first request and renewed whenever it expires.
+// It cannot be combined with WithAPIKey.
+func WithSessionCredentials(username, password string) Option {
+	return func(cfg *clientConfig) error {
+		if username == "" {
+			return errors.New("username must not be empty")
+		}
+		cfg.username = username
+		cfg.password = password
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the HTTP transport.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(cfg *clientConfig) error {
+		cfg.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
+// WithCACertificates trusts the PEM encoded CA certificates in addition to the system roots.
+func WithCACertificates(pem []byte) Option {
+	return func(cfg *clientConfig) error {
+		if cfg.rootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			cfg.rootCAs = pool
+		}
+		if !cfg.rootCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no CA certificates found in PEM data")
+		}
+		return nil
+	}
+}
+
+// WithCAFile trusts the PEM encoded CA bundle at path in addition to the system roots.
+func WithCAFile(path string) Option {
+	return func(cfg *clientConfig) error {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading CA bundle: %w", err)
+		}
+		return WithCACertificates(pem)(cfg)
+	}
+}
+
+// WithProxy sends all requests through the proxy at proxyURL instead of the one from the environment.
+func WithProxy(proxyURL string) Option {
+	return func(cfg *clientConfig) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		cfg.proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// WithTimeout limits the time of a single HTTP attempt including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests.
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *clientConfig) error {
+		cfg.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetryPolicy retries transient failures according to p.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(cfg *clientConfig) error {
+		cfg.retryPolicy = p
+		return nil
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond with bursts of up to burst requests.
+// Every attempt of a retried request counts against the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(cfg *clientConfig) error {
+		if requestsPerSecond <= 0 {
+			return errors.New("rate limit must be positive")
+		}
+		cfg.limiter = newRateLimiter(requestsPerSecond, burst)
+		return nil
+	}
+}
+
+// WithLogger logs every request at debug level to logger, and requests that failed or got
+// a non-2xx status at warn level.
+// Debug records include the request headers and the beginning of the request and response bodies.
+// API keys, session tokens, cookies and passwords are redacted, so the logs are safe to ship.
+// Use slog.New to adapt any slog.Handler.
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *clientConfig) error {
+		cfg.logger = logger
+		return nil
+	}
+}
+
+// WithBeforeRequest registers a hook called before each attempt of a request is sent.
+func WithBeforeRequest(hook BeforeRequestHook) Option {
+	return func(cfg *clientConfig) error {
+		cfg.beforeRequest = append(cfg.beforeRequest, hook)
+		return nil
+	}
+}
+
+// WithAfterResponse registers a hook called after each attempt of a request completed or failed.
+func WithAfterResponse(hook AfterResponseHook) Option {
+	return func(cfg *clientConfig) error {
+		cfg.afterResponse = append(cfg.afterResponse, hook)
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew_WithAPIKeyAndUserAgent(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("X-Apikey"), "accesskey=foo; secretkey=bar;"; got != want {
+			t.Errorf("X-Apikey: %q, want %q", got, want)
+		}
+		if got := r.Header.Get("User-Agent"); got != "nightly-sync/1.0" {
+			t.Errorf("User-Agent: %q, want nightly-sync/1.0", got)
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0}`)
+	})
+
+	var before, after []RequestInfo
+	c, err := New(server.URL,
+		WithAPIKey("foo", "bar"),
+		WithUserAgent("nightly-sync/1.0"),
+		WithTimeout(time.Minute),
+		WithRateLimit(100, 1),
+		WithBeforeRequest(func(ctx context.Context, info *RequestInfo) { before = append(before, *info) }),
+		WithAfterResponse(func(ctx context.Context, info *RequestInfo) { after = append(after, *info) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Authentication.Authenticated() {
+		t.Error("Expected the client to be authenticated")
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.CurrentUser.Get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(before) != 2 || len(after) != 2 {
+		t.Fatalf("Expected 2 calls of each hook, got %d and %d", len(before), len(after))
+	}
+	if after[0].Method != "GET" || after[0].Endpoint != "/rest/currentUser" || after[0].StatusCode != http.StatusOK {
+		t.Errorf("Unexpected request info %+v", after[0])
+	}
+	if before[0].StatusCode != 0 {
+		t.Errorf("Expected no status before the request was sent, got %d", before[0].StatusCode)
+	}
+}
+
+func TestNew_WithSessionCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	logins := 0
+	mux.HandleFunc("/rest/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		logins++
+		fmt.Fprint(w, `{"type":"regular","response":{"token":42},"error_code":0}`)
+	})
+	mux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-SecurityCenter"); got != "42" {
+			t.Errorf("X-SecurityCenter: %q, want 42", got)
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0}`)
+	})
+
+	c, err := New(server.URL, WithSessionCredentials("admin", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logins != 0 {
+		t.Error("Expected the session to be acquired lazily")
+	}
+	if _, _, err := c.CurrentUser.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CurrentUser.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 1 {
+		t.Errorf("Logins: %d, want 1", logins)
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	c, err := New(testTenableInstanceURL,
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+		WithProxy("http://proxy.example.com:3128"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := c.client.(*http.Client).Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Error("Expected the TLS config to be used")
+	}
+	req, _ := http.NewRequest("GET", testTenableInstanceURL, nil)
+	if proxy, err := transport.Proxy(req); err != nil || proxy.Host != "proxy.example.com:3128" {
+		t.Errorf("Unexpected proxy %v (%v)", proxy, err)
+	}
+
+	if _, err := New(testTenableInstanceURL, WithHTTPClient(http.DefaultClient), WithTimeout(time.Second)); err == nil {
+		t.Error("Expected an error when combining WithHTTPClient with transport options")
+	}
+	if _, err := New(testTenableInstanceURL, WithCACertificates([]byte("no certificate"))); err == nil {
+		t.Error("Expected an error for invalid CA certificates")
+	}
+	if _, err := New(testTenableInstanceURL, WithAPIKey("", "")); err == nil {
+		t.Error("Expected an error for an empty API key")
+	}
+	if _, err := New(testTenableInstanceURL, WithAPIKey("access", "secret"), WithSessionCredentials("admin", "pa55word")); err == nil {
+		t.Error("Expected an error when combining API key and session credentials")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// DefaultPageSize is the number of records requested per page if no page size is given.
+const DefaultPageSize = 1000
+
+// errStopWalk stops WalkPagesWithContext when an iterator consumer breaks out of its loop.
+var errStopWalk = errors.New("stop walking pages")
+
+// WalkPagesWithContext runs the analysis query in body page by page, starting at body.Query.StartOffset,
+// and calls fn for every page until totalRecords are fetched. The offsets are set from pageSize
+// (DefaultPageSize if not positive); body itself is not modified.
+// The paging fields StartAt, MaxResults and Total of the *Response are filled for every page.
+// If fn returns an error, walking stops and that error is returned.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) WalkPagesWithContext(ctx context.Context, body AnalysisBody, pageSize int, fn func(page *AnalysisResponse, resp *Response) error) error {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	start := body.Query.StartOffset
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		body.Query.StartOffset = start
+		body.Query.EndOffset = start + int64(pageSize)
+		page, resp, err := s.PostWithContext(ctx, body)
+		if err != nil {
+			return err
+		}
+		if err := fn(page, resp); err != nil {
+			return err
+		}
+
+		returned := int64(len(page.Response.Results))
+		start += returned
+		if returned == 0 || start >= int64(page.Response.TotalRecords) {
+			return nil
+		}
+	}
+}
+
+// WalkPages wraps WalkPagesWithContext using the background context.
+func (s *AnalysisService) WalkPages(body AnalysisBody, pageSize int, fn func(page *AnalysisResponse, resp *Response) error) error {
+	return s.WalkPagesWithContext(context.Background(), body, pageSize, fn)
+}
+
+// Pages returns an iterator over all pages of the analysis query in body, see WalkPagesWithContext.
+// A failing request is yielded as error and ends the iteration.
+//
+//	for page, err := range c.Analysis.Pages(ctx, body, 500) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (s *AnalysisService) Pages(ctx context.Context, body AnalysisBody, pageSize int) iter.Seq2[*AnalysisResponse, error] {
+	return func(yield func(*AnalysisResponse, error) bool) {
+		err := s.WalkPagesWithContext(ctx, body, pageSize, func(page *AnalysisResponse, _ *Response) error {
+			if !yield(page, nil) {
+				return errStopWalk
+			}
+			return nil
+		})
+		if err != nil && err != errStopWalk {
+			yield(nil, err)
+		}
+	}
+}
+
+// All returns an iterator over every result of the analysis query in body, fetched page by page.
+// A failing request is yielded as error and ends the iteration.
+func (s *AnalysisService) All(ctx context.Context, body AnalysisBody, pageSize int) iter.Seq2[Analysis, error] {
+	return func(yield func(Analysis, error) bool) {
+		for page, err := range s.Pages(ctx, body, pageSize) {
+			if err != nil {
+				yield(Analysis{}, err)
+				return
+			}
+			for _, result := range page.Response.Results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+		}
+	}
+}
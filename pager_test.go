@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// testPagedAnalysis serves total listvuln records honoring startOffset and endOffset
+// and records the requested offsets.
+func testPagedAnalysis(t *testing.T, total int, offsets *[][2]int64) {
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body AnalysisBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		start, end := body.Query.StartOffset, body.Query.EndOffset
+		*offsets = append(*offsets, [2]int64{start, end})
+		if end > int64(total) {
+			end = int64(total)
+		}
+		rs := AnalysisResultSet{TotalRecords: Int(total), StartOffset: Int(body.Query.StartOffset), EndOffset: Int(body.Query.EndOffset)}
+		for i := start; i < end; i++ {
+			rs.Results = append(rs.Results, Analysis{PluginID: ID(i)})
+		}
+		rs.ReturnedRecords = Int(len(rs.Results))
+		json.NewEncoder(w).Encode(AnalysisResponse{Type: "regular", Response: rs})
+	})
+}
+
+func TestAnalysisService_WalkPages(t *testing.T) {
+	setup()
+	defer teardown()
+	var offsets [][2]int64
+	testPagedAnalysis(t, 25, &offsets)
+
+	var ids []ID
+	var responses []Response
+	err := testClient.Analysis.WalkPages(AnalysisBody{Type: "vuln"}, 10, func(page *AnalysisResponse, resp *Response) error {
+		for _, r := range page.Response.Results {
+			ids = append(ids, r.PluginID)
+		}
+		responses = append(responses, *resp)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 25 || ids[24] != 24 {
+		t.Fatalf("Unexpected results %v", ids)
+	}
+	want := [][2]int64{{0, 10}, {10, 20}, {20, 30}}
+	if len(offsets) != len(want) {
+		t.Fatalf("Offsets: %v, want %v", offsets, want)
+	}
+	for i := range want {
+		if offsets[i] != want[i] {
+			t.Errorf("Offsets: %v, want %v", offsets, want)
+		}
+	}
+	if last := responses[2]; last.StartAt != 20 || last.MaxResults != 10 || last.Total != 25 {
+		t.Errorf("Unexpected paging values %d %d %d", last.StartAt, last.MaxResults, last.Total)
+	}
+}
+
+func TestAnalysisService_All(t *testing.T) {
+	setup()
+	defer teardown()
+	var offsets [][2]int64
+	testPagedAnalysis(t, 7, &offsets)
+
+	count := 0
+	for result, err := range testClient.Analysis.All(context.Background(), AnalysisBody{Type: "vuln"}, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.PluginID != ID(count) {
+			t.Errorf("PluginID: %d, want %d", result.PluginID, count)
+		}
+		count++
+	}
+	if count != 7 || len(offsets) != 3 {
+		t.Errorf("Got %d results in %d pages, want 7 in 3", count, len(offsets))
+	}
+
+	// breaking out of the loop stops fetching pages
+	offsets = nil
+	for page, err := range testClient.Analysis.Pages(context.Background(), AnalysisBody{Type: "vuln"}, 3) {
+		if err != nil || len(page.Response.Results) != 3 {
+			t.Fatalf("Unexpected page %+v (%v)", page, err)
+		}
+		break
+	}
+	if len(offsets) != 1 {
+		t.Errorf("Expected a single request, got %d", len(offsets))
+	}
+}
+
+func TestAnalysisService_PagesError(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"regular","response":"","error_code":146,"error_msg":"Invalid parameters."}`))
+	})
+
+	var errs []error
+	for _, err := range testClient.Analysis.Pages(context.Background(), AnalysisBody{}, 10) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], &APIError{Code: 146}) {
+		t.Errorf("Expected a single error_code 146, got %v", errs)
+	}
+}
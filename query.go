@@ -0,0 +1,641 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryError is a syntax or filter error of a text query.
+type QueryError struct {
+	// Column is the 1-based byte position of the error in the query text
+	Column int
+	Msg    string
+	// Err is the underlying filter error, if any
+	Err error
+}
+
+func (e *QueryError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("tenable: query column %d: %v", e.Column, e.Err)
+	}
+	return fmt.Sprintf("tenable: query column %d: %s", e.Column, e.Msg)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// QueryParser compiles the text form of analysis filters, e.g.
+//
+//	severity >= high AND exploitAvailable = true AND lastSeen within 30d AND repository in ("repo1")
+//
+// Clauses are joined by AND and have one of the forms
+//
+//	name op value              op is one of = != >= <= ~=
+//	name in (value, ...)
+//	name not in (value, ...)
+//	name within 30d            day filters like lastSeen, firstSeen and pluginPublished
+//	name older than 30d
+//
+// Values are bare words (high, 10.0.0.0/8, CVE-2021-44228) or double quoted strings.
+// Keywords are case insensitive.
+type QueryParser struct {
+	// Resolve maps names of referenced objects, e.g. repository names, to their IDs.
+	// If nil, only numeric IDs are accepted for repository, family and assetID.
+	Resolve func(filter, name string) (ID, error)
+}
+
+// ParseFilters compiles the text query src into analysis filters with the zero QueryParser.
+// It has no Resolve function, so repositories must be given by numeric ID. AnalysisService.ParseFilters
+// also resolves repository names, e.g. repository in ("repo1").
+func ParseFilters(src string) ([]AnalysisFilter, error) {
+	return (&QueryParser{}).Parse(src)
+}
+
+// ParseFiltersWithContext compiles the text query src into analysis filters.
+// Repository names are resolved to their IDs with the repositories of Tenable, which are
+// only fetched if the query names a repository.
+func (s *AnalysisService) ParseFiltersWithContext(ctx context.Context, src string) ([]AnalysisFilter, error) {
+	return (&QueryParser{Resolve: s.client.Repository.ResolverWithContext(ctx)}).Parse(src)
+}
+
+// ParseFilters wraps ParseFiltersWithContext using the background context.
+func (s *AnalysisService) ParseFilters(src string) ([]AnalysisFilter, error) {
+	return s.ParseFiltersWithContext(context.Background(), src)
+}
+
+// RepositoryResolver returns a QueryParser.Resolve function looking up repository names in repos.
+func RepositoryResolver(repos []Repository) func(filter, name string) (ID, error) {
+	return func(filter, name string) (ID, error) {
+		if filter == "repository" {
+			for _, r := range repos {
+				if r.Name == name {
+					return r.ID, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("%w: unknown %s %q", ErrInvalidFilter, filter, name)
+	}
+}
+
+// Parse compiles the text query src into analysis filters, ready to be assigned to AnalysisQuery.Filters.
+// Errors are of type *QueryError and carry the column of the offending token.
+func (p *QueryParser) Parse(src string) ([]AnalysisFilter, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	ps := &queryParser{QueryParser: p, tokens: tokens}
+	var filters []AnalysisFilter
+	if ps.peek().kind == tokEOF {
+		return nil, nil
+	}
+	for {
+		f, err := ps.clause()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+		tok := ps.next()
+		if tok.kind == tokEOF {
+			return filters, nil
+		}
+		if !tok.isKeyword("and") {
+			return nil, tok.errorf("expected AND, got %s", tok)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the 0-based byte offset in the query
+	pos int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t token) isKeyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (t token) errorf(format string, args ...interface{}) *QueryError {
+	return &QueryError{Column: t.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isWordByte reports whether c may be part of a bare word.
+func isWordByte(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '(', ')', ',', '"', '=', '!', '<', '>', '~':
+		return false
+	}
+	return true
+}
+
+func lexQuery(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{tokOp, "=", i})
+			i++
+		case c == '!' || c == '<' || c == '>' || c == '~':
+			if i+1 >= len(src) || src[i+1] != '=' {
+				return nil, &QueryError{Column: i + 1, Msg: fmt.Sprintf("unexpected %q, operators are = != >= <= ~=", c)}
+			}
+			tokens = append(tokens, token{tokOp, src[i : i+2], i})
+			i += 2
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, &QueryError{Column: i + 1, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{tokString, b.String(), i})
+			i = j + 1
+		default:
+			j := i
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokWord, src[i:j], i})
+			i = j
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(src)}), nil
+}
+
+type queryParser struct {
+	*QueryParser
+	tokens []token
+	i      int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// value returns the next token if it is a word or string.
+func (p *queryParser) value() (token, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		return t, t.errorf("expected a value, got %s", t)
+	}
+	return t, nil
+}
+
+// list parses "(value, ...)".
+func (p *queryParser) list() ([]token, error) {
+	if t := p.next(); t.kind != tokLParen {
+		return nil, t.errorf("expected (, got %s", t)
+	}
+	var values []token
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRParen:
+			return values, nil
+		default:
+			return nil, t.errorf("expected , or ), got %s", t)
+		}
+	}
+}
+
+// clause parses a single filter.
+func (p *queryParser) clause() (AnalysisFilter, error) {
+	nameTok := p.next()
+	if nameTok.kind != tokWord {
+		return AnalysisFilter{}, nameTok.errorf("expected a filter name, got %s", nameTok)
+	}
+	name, spec, ok := lookupFilter(nameTok.text)
+	if !ok {
+		return AnalysisFilter{}, nameTok.errorf("unknown filter %q", nameTok.text)
+	}
+
+	var op FilterOperator
+	var values []token
+	var err error
+	switch t := p.next(); {
+	case t.kind == tokOp:
+		op = FilterOperator(t.text)
+		var v token
+		v, err = p.value()
+		values = []token{v}
+	case t.isKeyword("in"):
+		op = OpEqual
+		values, err = p.list()
+	case t.isKeyword("not"):
+		if in := p.next(); !in.isKeyword("in") {
+			return AnalysisFilter{}, in.errorf("expected IN after NOT, got %s", in)
+		}
+		op = OpNotEqual
+		values, err = p.list()
+	case t.isKeyword("within"), t.isKeyword("older"):
+		if spec.kind != kindDays {
+			return AnalysisFilter{}, t.errorf("%s is not a day filter", name)
+		}
+		if t.isKeyword("older") && p.peek().isKeyword("than") {
+			p.next()
+		}
+		d, err := p.value()
+		if err != nil {
+			return AnalysisFilter{}, err
+		}
+		days, convErr := strconv.Atoi(strings.TrimSuffix(strings.ToLower(d.text), "d"))
+		if convErr != nil || days < 0 {
+			return AnalysisFilter{}, d.errorf("expected a number of days like 30d, got %s", d)
+		}
+		r := WithinDays(days)
+		if t.isKeyword("older") {
+			r = OlderThanDays(days)
+		}
+		return wrapFilterError(nameTok)(daysFilter(name, r))
+	default:
+		return AnalysisFilter{}, t.errorf("expected an operator, IN, NOT IN, WITHIN or OLDER THAN after %s, got %s", name, t)
+	}
+	if err != nil {
+		return AnalysisFilter{}, err
+	}
+	return p.build(nameTok, name, spec, op, values)
+}
+
+// lookupFilter finds a predefined filter by case insensitive name.
+func lookupFilter(s string) (string, filterSpec, bool) {
+	if spec, ok := filterSpecs[s]; ok {
+		return s, spec, true
+	}
+	for name, spec := range filterSpecs {
+		if strings.EqualFold(name, s) {
+			return name, spec, true
+		}
+	}
+	return "", filterSpec{}, false
+}
+
+func wrapFilterError(t token) func(AnalysisFilter, error) (AnalysisFilter, error) {
+	return func(f AnalysisFilter, err error) (AnalysisFilter, error) {
+		if err != nil {
+			return AnalysisFilter{}, &QueryError{Column: t.pos + 1, Err: err}
+		}
+		return f, nil
+	}
+}
+
+var protocolNames = map[string]int{"icmp": 1, "tcp": 6, "udp": 17}
+
+// build converts the parsed values of a clause into a filter using the typed constructors.
+func (p *queryParser) build(nameTok token, name string, spec filterSpec, op FilterOperator, values []token) (AnalysisFilter, error) {
+	wrap := wrapFilterError(nameTok)
+	if !containsOperator(spec.operators, op) && spec.kind != kindSeverity && spec.kind != kindScore {
+		return wrap(NewFilter(name, op, nil))
+	}
+	single := func() (token, error) {
+		if len(values) != 1 {
+			return token{}, values[1].errorf("%s takes a single value", name)
+		}
+		return values[0], nil
+	}
+
+	switch spec.kind {
+	case kindNumbers:
+		numbers := make([]int, len(values))
+		for i, v := range values {
+			n, err := strconv.Atoi(v.text)
+			if err != nil {
+				if proto, ok := protocolNames[strings.ToLower(v.text)]; ok && name == "protocol" {
+					n = proto
+				} else {
+					return AnalysisFilter{}, v.errorf("expected a number, got %s", v)
+				}
+			}
+			numbers[i] = n
+		}
+		switch name {
+		case "port":
+			return wrap(PortFilter(op, numbers...))
+		case "protocol":
+			return wrap(ProtocolFilter(op, numbers...))
+		}
+		return wrap(PluginIDFilter(op, numbers...))
+
+	case kindSeverity:
+		levels := make([]SeverityLevel, len(values))
+		for i, v := range values {
+			l, err := ParseSeverityLevel(v.text)
+			if err != nil {
+				return AnalysisFilter{}, &QueryError{Column: v.pos + 1, Err: err}
+			}
+			levels[i] = l
+		}
+		if op == OpGreaterOrEqual || op == OpLessOrEqual {
+			if _, err := single(); err != nil {
+				return AnalysisFilter{}, err
+			}
+			if op == OpGreaterOrEqual {
+				return wrap(SeverityAtLeastFilter(levels[0]))
+			}
+			var below []SeverityLevel
+			for l := SeverityInfo; l <= levels[0]; l++ {
+				below = append(below, l)
+			}
+			return wrap(SeverityFilter(OpEqual, below...))
+		}
+		return wrap(SeverityFilter(op, levels...))
+
+	case kindStrings:
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = v.text
+		}
+		if name == "cveID" {
+			return wrap(CVEIDFilter(op, strs...))
+		}
+		return wrap(stringsFilter(name, op, strs))
+
+	case kindObjects:
+		ids := make([]ID, len(values))
+		for i, v := range values {
+			id, err := p.resolve(name, v)
+			if err != nil {
+				return AnalysisFilter{}, err
+			}
+			ids[i] = id
+		}
+		return wrap(objectsFilter(name, op, ids))
+
+	case kindDays:
+		v, err := single()
+		if err != nil {
+			return AnalysisFilter{}, err
+		}
+		r, err := ParseDayRange(v.text)
+		if err != nil {
+			return AnalysisFilter{}, &QueryError{Column: v.pos + 1, Err: err}
+		}
+		return wrap(daysFilter(name, r))
+
+	case kindBool:
+		v, err := single()
+		if err != nil {
+			return AnalysisFilter{}, err
+		}
+		b, err := strconv.ParseBool(v.text)
+		if err != nil {
+			return AnalysisFilter{}, v.errorf("expected true or false, got %s", v)
+		}
+		return wrap(NewFilter(name, op, strconv.FormatBool(b)))
+
+	case kindScore:
+		v, err := single()
+		if err != nil {
+			return AnalysisFilter{}, err
+		}
+		min, max, err := parseScoreRange(op, v.text)
+		if err != nil {
+			return AnalysisFilter{}, v.errorf("%v", err)
+		}
+		return wrap(scoreFilter(name, min, max))
+	}
+
+	v, err := single()
+	if err != nil {
+		return AnalysisFilter{}, err
+	}
+	if name == "pluginType" {
+		return wrap(PluginTypeFilter(v.text))
+	}
+	return wrap(textFilter(name, op, v.text))
+}
+
+// resolve converts an object reference, a numeric ID or a name, to an ID.
+func (p *queryParser) resolve(name string, v token) (ID, error) {
+	if id, err := strconv.ParseInt(v.text, 10, 64); err == nil && v.kind == tokWord {
+		return ID(id), nil
+	}
+	if p.Resolve == nil {
+		return 0, v.errorf("cannot resolve %s %s, use its numeric ID, AnalysisService.ParseFilters or set QueryParser.Resolve", name, v)
+	}
+	id, err := p.Resolve(name, v.text)
+	if err != nil {
+		return 0, &QueryError{Column: v.pos + 1, Err: err}
+	}
+	return id, nil
+}
+
+// parseScoreRange parses ">= 7", "<= 4" or "= 7.0-10.0".
+func parseScoreRange(op FilterOperator, s string) (float64, float64, error) {
+	switch op {
+	case OpGreaterOrEqual, OpLessOrEqual:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("expected a score, got %q", s)
+		}
+		if op == OpGreaterOrEqual {
+			return n, 10, nil
+		}
+		return 0, n, nil
+	case OpEqual:
+		lo, hi, ok := strings.Cut(s, "-")
+		min, err1 := strconv.ParseFloat(lo, 64)
+		max, err2 := strconv.ParseFloat(hi, 64)
+		if !ok || err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("expected a score range like 7.0-10.0, got %q", s)
+		}
+		return min, max, nil
+	}
+	return 0, 0, fmt.Errorf("operator %q is not valid for scores, use >=, <= or =", op)
+}
+
+// FormatQuery returns the text form of the filters of q, see QueryParser.
+func FormatQuery(q AnalysisQuery) (string, error) {
+	return FormatFilters(q.Filters)
+}
+
+// FormatFilters returns the text form of filters, which ParseFilters compiles back to the same filters.
+// Referenced objects like repositories are printed by ID.
+func FormatFilters(filters []AnalysisFilter) (string, error) {
+	clauses := make([]string, len(filters))
+	for i, f := range filters {
+		c, err := formatFilter(f)
+		if err != nil {
+			return "", err
+		}
+		clauses[i] = c
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+func formatFilter(f AnalysisFilter) (string, error) {
+	name := f.FilterName
+	if name == "" {
+		name = f.ID
+	}
+	spec, ok := filterSpecs[name]
+	if !ok {
+		return "", fmt.Errorf("%w: cannot format filter %q", ErrInvalidFilter, name)
+	}
+	op := FilterOperator(f.Operator)
+
+	if spec.kind == kindObjects {
+		raw, err := json.Marshal(f.Value)
+		if err != nil {
+			return "", err
+		}
+		var objects []filterObject
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			return "", fmt.Errorf("%w: %s value %s", ErrInvalidFilter, name, raw)
+		}
+		ids := make([]string, len(objects))
+		for i, o := range objects {
+			ids[i] = strconv.FormatInt(int64(o.ID), 10)
+		}
+		return formatList(name, op, ids), nil
+	}
+
+	value, ok := f.Value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s value %v is no string", ErrInvalidFilter, name, f.Value)
+	}
+	switch spec.kind {
+	case kindNumbers, kindStrings:
+		return formatList(name, op, strings.Split(value, ",")), nil
+
+	case kindSeverity:
+		var levels []string
+		lo, hi := int(SeverityCritical)+1, -1
+		for _, s := range strings.Split(value, ",") {
+			l, err := ParseSeverityLevel(s)
+			if err != nil {
+				return "", err
+			}
+			levels = append(levels, l.String())
+			lo, hi = min(lo, int(l)), max(hi, int(l))
+		}
+		contiguous := hi-lo+1 == len(levels)
+		if op == OpEqual && len(levels) > 1 && contiguous && hi == int(SeverityCritical) {
+			return fmt.Sprintf("%s >= %s", name, SeverityLevel(lo)), nil
+		}
+		if op == OpEqual && len(levels) > 1 && contiguous && lo == int(SeverityInfo) {
+			return fmt.Sprintf("%s <= %s", name, SeverityLevel(hi)), nil
+		}
+		return formatList(name, op, levels), nil
+
+	case kindDays:
+		r, err := ParseDayRange(value)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case r.To < 0:
+			return fmt.Sprintf("%s older than %dd", name, r.From), nil
+		case r.From == 0:
+			return fmt.Sprintf("%s within %dd", name, r.To), nil
+		}
+		return fmt.Sprintf("%s %s %s", name, op, r), nil
+
+	case kindScore:
+		min, max, err := parseScoreRange(OpEqual, value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s %v", ErrInvalidFilter, name, err)
+		}
+		switch {
+		case max == 10:
+			return fmt.Sprintf("%s >= %s", name, strconv.FormatFloat(min, 'f', -1, 64)), nil
+		case min == 0:
+			return fmt.Sprintf("%s <= %s", name, strconv.FormatFloat(max, 'f', -1, 64)), nil
+		}
+		return fmt.Sprintf("%s %s %s", name, op, value), nil
+	}
+	return fmt.Sprintf("%s %s %s", name, op, quoteValue(value)), nil
+}
+
+// formatList prints a single value with its operator and several values with IN or NOT IN.
+func formatList(name string, op FilterOperator, values []string) string {
+	for i, v := range values {
+		values[i] = quoteValue(v)
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf("%s %s %s", name, op, values[0])
+	}
+	list := "(" + strings.Join(values, ", ") + ")"
+	if op == OpNotEqual {
+		return fmt.Sprintf("%s not in %s", name, list)
+	}
+	return fmt.Sprintf("%s in %s", name, list)
+}
+
+// quoteValue returns v as bare word if possible, else as double quoted string.
+func quoteValue(v string) string {
+	bare := v != ""
+	for i := 0; i < len(v) && bare; i++ {
+		bare = isWordByte(v[i])
+	}
+	switch strings.ToLower(v) {
+	case "and", "in", "not", "within", "older", "than":
+		bare = false
+	}
+	if bare {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
@@ -0,0 +1,149 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQueryParser_Parse(t *testing.T) {
+	p := &QueryParser{Resolve: RepositoryResolver([]Repository{{ID: 1, Name: "repo1"}, {ID: 7, Name: "DMZ"}})}
+	filters, err := p.Parse(`severity >= high AND exploitAvailable = true and lastSeen within 30d AND repository in ("repo1", DMZ)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(filters)
+	want := `[{"id":"severity","filterName":"severity","operator":"=","type":"vuln","isPredefined":true,"value":"3,4"},` +
+		`{"id":"exploitAvailable","filterName":"exploitAvailable","operator":"=","type":"vuln","isPredefined":true,"value":"true"},` +
+		`{"id":"lastSeen","filterName":"lastSeen","operator":"=","type":"vuln","isPredefined":true,"value":"0:30"},` +
+		`{"id":"repository","filterName":"repository","operator":"=","type":"vuln","isPredefined":true,"value":[{"id":"1"},{"id":"7"}]}]`
+	if string(got) != want {
+		t.Errorf("Got %s\nwant %s", got, want)
+	}
+}
+
+func TestAnalysisService_ParseFilters(t *testing.T) {
+	setup()
+	defer teardown()
+	requests := 0
+	testMux.HandleFunc("/rest/repository", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		requests++
+		fmt.Fprint(w, `{"type":"regular","response":[{"id":"1","name":"repo1"},{"id":"7","name":"DMZ"}],"error_code":0}`)
+	})
+
+	// the example of the query language
+	const query = `severity >= high AND exploitAvailable = true AND lastSeen within 30d AND repository in ("repo1")`
+	filters, err := testClient.Analysis.ParseFilters(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(filters[3].Value); len(filters) != 4 || string(got) != `[{"id":"1"}]` {
+		t.Errorf("Unexpected filters %+v", filters)
+	}
+
+	// numeric IDs need no repository list
+	if _, err := testClient.Analysis.ParseFilters(`repository = 3`); err != nil || requests != 1 {
+		t.Errorf("Got error %v and %d repository requests, want 1", err, requests)
+	}
+	if _, err := testClient.Analysis.ParseFilters(`repository = unknown`); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("Expected an unknown repository error, got %v", err)
+	}
+
+	// the package level ParseFilters only takes IDs
+	_, err = ParseFilters(query)
+	var qerr *QueryError
+	if !errors.As(err, &qerr) || qerr.Column != 89 || !strings.Contains(err.Error(), "AnalysisService.ParseFilters") {
+		t.Errorf("Expected an error pointing to AnalysisService.ParseFilters, got %v", err)
+	}
+}
+
+func TestQueryParser_Errors(t *testing.T) {
+	tests := []struct {
+		query  string
+		column int
+		filter bool
+	}{
+		{`severity >= high AND bogus = 1`, 22, false},
+		{`severity >= high severity = low`, 18, false},
+		{`pluginID > 5`, 10, false},
+		{`ip ~= 10.0.0.1`, 1, true},
+		{`port = 70000`, 1, true},
+		{`port in (80, https)`, 14, false},
+		{`repository = repo1`, 14, false},
+		{`pluginName = "OpenSSL`, 14, false},
+		{`severity within 30d`, 10, false},
+		{`severity = severe`, 12, true},
+		{`lastSeen within`, 16, false},
+		{`lastSeen within -5d`, 17, false},
+		{`firstSeen older than -1d`, 22, false},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilters(tt.query)
+		var qerr *QueryError
+		if !errors.As(err, &qerr) {
+			t.Errorf("%s: expected a *QueryError, got %v", tt.query, err)
+			continue
+		}
+		if qerr.Column != tt.column {
+			t.Errorf("%s: column %d, want %d (%v)", tt.query, qerr.Column, tt.column, err)
+		}
+		if errors.Is(err, ErrInvalidFilter) != tt.filter {
+			t.Errorf("%s: unexpected error %v", tt.query, err)
+		}
+	}
+}
+
+func TestFormatFilters_RoundTrip(t *testing.T) {
+	queries := []string{
+		`severity >= high AND exploitAvailable = true AND lastSeen within 30d AND repository in (1, 7)`,
+		`severity in (info, critical) AND severity != low AND severity <= medium`,
+		`pluginID in (14272, 11219) AND pluginID >= 100000 AND port not in (80, 443) AND protocol = 6`,
+		`ip = 10.0.0.0/8 AND cveID in (CVE-2021-44228, CVE-2021-45046) AND dnsName ~= example.com`,
+		`firstSeen older than 90d AND pluginPublished = 7:30 AND vprScore >= 7.5 AND cvssV3BaseScore = 4.0-6.9`,
+		`pluginName ~= "Apache Log4j" AND pluginText ~= "\"quoted\"" AND pluginType = passive AND family = 6`,
+	}
+	for _, q := range queries {
+		filters, err := ParseFilters(q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		text, err := FormatQuery(AnalysisQuery{Filters: filters})
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if text != q {
+			t.Errorf("Got %s\nwant %s", text, q)
+		}
+	}
+}
+
+func TestFormatFilters_DecodedQuery(t *testing.T) {
+	// filters as returned by the API for a saved query
+	raw := `[{"filterName":"repository","operator":"=","value":[{"id":"3","name":"Staging"}]},
+		{"filterName":"protocol","operator":"=","value":"6,17"}]`
+	var filters []AnalysisFilter
+	if err := json.Unmarshal([]byte(raw), &filters); err != nil {
+		t.Fatal(err)
+	}
+	text, err := FormatFilters(filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `repository = 3 AND protocol in (6, 17)`; text != want {
+		t.Errorf("Got %s, want %s", text, want)
+	}
+
+	if _, err := FormatFilters([]AnalysisFilter{{FilterName: "custom", Value: "x"}}); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("Expected ErrInvalidFilter for unknown filters, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package recorder provides an http.RoundTripper which records real exchanges with Tenable
+// into cassette files and replays them in tests.
+//
+// Record once against a real instance:
+//
+//	rec, err := recorder.New("testdata/analysis.json", recorder.ModeRecord)
+//	rec.Transport = &tenable.APIKeyAuthTransport{APIKey: key, APISecret: secret}
+//	c, err := tenable.NewClient(rec.Client(), url)
+//	...
+//	err = rec.Save()
+//
+// and replay deterministically afterwards:
+//
+//	rec, err := recorder.New("testdata/analysis.json", recorder.ModeReplayStrict)
+//	c, err := tenable.NewClient(rec.Client(), "https://sc.example.com/")
+//
+// Credentials (API keys, session tokens, cookies and passwords) are scrubbed before they are written.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	tenable "github.com/IBM/go-tenable"
+)
+
+// Mode controls whether a Recorder talks to the real server.
+type Mode int
+
+const (
+	// ModeRecord sends all requests to the real server and records the exchanges.
+	ModeRecord Mode = iota
+	// ModeReplay serves recorded exchanges and sends unmatched requests to the real server.
+	ModeReplay
+	// ModeReplayStrict serves recorded exchanges and fails on unmatched requests.
+	ModeReplayStrict
+)
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording exchanges into or replaying them from a cassette file.
+type Recorder struct {
+	// Transport is the underlying HTTP transport used to reach the real server.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	replayed []bool
+}
+
+// New returns a Recorder for the cassette file at path.
+// In the replay modes the cassette must exist; in ModeRecord it is written by Save.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading cassette: %w", err)
+	}
+	if err := json.Unmarshal(raw, &r.cassette); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	r.replayed = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Client returns an *http.Client using the Recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns a copy of the recorded or loaded interactions.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file. It is a no-op in the replay modes.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	raw, err := json.MarshalIndent(r.cassette, "", "\t")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, raw, 0o644)
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode != ModeRecord {
+		if resp, ok := r.replay(req, body); ok {
+			return resp, nil
+		}
+		if r.mode == ModeReplayStrict {
+			return nil, fmt.Errorf("recorder: no recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+		}
+		return r.transport().RoundTrip(req)
+	}
+
+	resp, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.Query().Encode(),
+			Header: tenable.RedactHeader(req.Header),
+			Body:   string(tenable.RedactBody(body)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     tenable.RedactHeader(resp.Header),
+			Body:       string(tenable.RedactBody(respBody)),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay returns the response of the first unused interaction matching req.
+// Once all matching interactions were used, the last one is served again.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query := req.URL.Query().Encode()
+	normalized := normalizeBody(body)
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		recorded := interaction.Request
+		if recorded.Method != req.Method || recorded.Path != req.URL.Path || recorded.Query != query ||
+			normalizeBody([]byte(recorded.Body)) != normalized {
+			continue
+		}
+		match = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, false
+	}
+	r.replayed[match] = true
+
+	recorded := r.cassette.Interactions[match].Response
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, true
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.Transport != nil {
+		return r.Transport
+	}
+	return http.DefaultTransport
+}
+
+// readBody returns the body of req without changing req. The body is read from req.GetBody if it is set,
+// otherwise req is consumed and a clone of it carrying a copy of the body is returned to be sent instead.
+func readBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		return req, body, err
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone, body, nil
+}
+
+// normalizeBody returns a canonical form of JSON bodies so that key order and whitespace
+// do not matter when matching. Other bodies are compared as they are.
+// Credentials are scrubbed first, the same way they are in recorded bodies.
+func normalizeBody(body []byte) string {
+	body = tenable.RedactBody(bytes.TrimSpace(body))
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	// encoding/json sorts map keys, which gives a canonical representation
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(canonical)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tenable "github.com/IBM/go-tenable"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/rest/repository":
+			fmt.Fprint(w, `{"type":"regular","response":[{"id":"1","name":"repo1"}],"error_code":0}`)
+		case "/rest/analysis":
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, `{"type":"regular","response":{"totalRecords":"1","results":[{"pluginID":"19506","name":"%t"}]},"error_code":0}`, strings.Contains(string(body), "listvuln"))
+		}
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := New(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Transport = &tenable.APIKeyAuthTransport{APIKey: "access-secret", APISecret: "secret-secret", Transport: http.DefaultTransport}
+
+	c, err := tenable.NewClient(rec.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Authentication.SetAPIKeyAuth("access-secret", "secret-secret")
+	if _, _, err := c.Repository.Get("All", "id,name"); err != nil {
+		t.Fatal(err)
+	}
+	body := tenable.AnalysisBody{Type: "vuln", Query: tenable.AnalysisQuery{Tool: "listvuln", Type: "vuln"}}
+	if _, _, err := c.Analysis.Post(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("access-secret")) || bytes.Contains(raw, []byte("secret-secret")) {
+		t.Errorf("Cassette contains credentials:\n%s", raw)
+	}
+	if len(rec.Interactions()) != 2 {
+		t.Fatalf("Expected 2 interactions, got %d", len(rec.Interactions()))
+	}
+
+	// replay against a server which is not reachable
+	server.Close()
+	calls = 0
+	replay, err := New(cassette, ModeReplayStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = tenable.NewClient(replay.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos, _, err := c.Repository.Get("All", "id,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].Name != "repo1" {
+		t.Errorf("Unexpected repositories %+v", repos)
+	}
+	analysis, _, err := c.Analysis.Post(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.Response.Results[0].Name != "true" {
+		t.Errorf("Unexpected analysis %+v", analysis.Response)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no calls to the server, got %d", calls)
+	}
+
+	// a different query or body does not match
+	if _, _, err := c.Repository.Get("Local", ""); err == nil {
+		t.Error("Expected an error for an unmatched request")
+	}
+	body.Query.Tool = "sumip"
+	if _, _, err := c.Analysis.Post(body); err == nil {
+		t.Error("Expected an error for an unmatched body")
+	}
+}
+
+func TestRecorder_SessionLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/token":
+			http.SetCookie(w, &http.Cookie{Name: "TNS_SESSIONID", Value: "cookie-secret"})
+			fmt.Fprint(w, `{"type":"regular","response":{"token":1337,"releaseSession":false},"error_code":0}`)
+		case "/rest/repository":
+			fmt.Fprint(w, `{"type":"regular","response":[{"id":"1","name":"repo1"}],"error_code":0}`)
+		}
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := New(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := tenable.NewClient(rec.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Authentication.AcquireSessionCookie("admin", "password-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Repository.Get("All", "id,name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"1337", "cookie-secret", "password-secret"} {
+		if bytes.Contains(raw, []byte(secret)) {
+			t.Errorf("Cassette contains %s:\n%s", secret, raw)
+		}
+	}
+
+	server.Close()
+	replay, err := New(cassette, ModeReplayStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = tenable.NewClient(replay.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Authentication.AcquireSessionCookie("admin", "password-secret"); err != nil {
+		t.Fatalf("Replaying the login: %v", err)
+	}
+	if !c.Authentication.Authenticated() {
+		t.Error("Expected an authenticated client")
+	}
+	repos, _, err := c.Repository.Get("All", "id,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].Name != "repo1" {
+		t.Errorf("Unexpected repositories %+v", repos)
+	}
+}
+
+func TestRecorder_RoundTripKeepsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	rec, err := New(filepath.Join(t.TempDir(), "cassette.json"), ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, withGetBody := range []bool{true, false} {
+		req, err := http.NewRequest("POST", server.URL, strings.NewReader(`{"name":"x"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !withGetBody {
+			req.GetBody = nil
+		}
+		body := req.Body
+		resp, err := rec.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		echoed, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(echoed) != `{"name":"x"}` {
+			t.Errorf("Server got body %q", echoed)
+		}
+		if req.Body != body {
+			t.Error("Expected the request body to be unchanged")
+		}
+	}
+	for _, interaction := range rec.Interactions() {
+		if interaction.Request.Body != `{"name":"x"}` {
+			t.Errorf("Recorded body %q", interaction.Request.Body)
+		}
+	}
+}
+
+func TestNormalizeBody(t *testing.T) {
+	a := normalizeBody([]byte(`{"b": 1, "a": {"y": [1, 2], "x": "z"}}`))
+	b := normalizeBody([]byte("{\"a\":{\"x\":\"z\",\"y\":[1,2]},\"b\":1}\n"))
+	if a != b {
+		t.Errorf("Expected equal normalized bodies, got %s and %s", a, b)
+	}
+	if got := normalizeBody([]byte("plain text")); got != "plain text" {
+		t.Errorf("Unexpected normalized body %q", got)
+	}
+}
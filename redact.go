@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// redacted replaces credentials in headers and bodies.
+const redacted = "REDACTED"
+
+// sensitiveHeaders are the headers carrying credentials.
+var sensitiveHeaders = []string{"X-Apikey", sessionTokenHeader, "Cookie", "Set-Cookie", "Authorization", "Proxy-Authorization"}
+
+// sensitiveFields matches JSON members carrying credentials, e.g. the password of a /rest/token login
+// or the token of its response. It also works on truncated bodies.
+// Numbers are matched separately from strings, so that they are replaced by a number.
+var sensitiveFields = regexp.MustCompile(`("(?i:password|secretKey|accessKey|apiKey|token)"\s*:\s*)(?:("(?:[^"\\]|\\.)*"?)|(-?[0-9][0-9.eE+-]*))`)
+
+// sessionCookie matches the value of a TNS_SESSIONID cookie in header values.
+var sessionCookie = regexp.MustCompile(`(TNS_SESSIONID=)[^;\s]*`)
+
+// RedactHeader returns a copy of h with all credentials (API keys, session tokens and cookies) replaced.
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return nil
+	}
+	for _, name := range sensitiveHeaders {
+		if values := out.Values(name); len(values) > 0 {
+			out[http.CanonicalHeaderKey(name)] = []string{redacted}
+		}
+	}
+	return out
+}
+
+// RedactBody returns body with the values of credential fields like "password" and "token" replaced.
+// Strings are replaced by "REDACTED" and numbers by 0, so redacted JSON still decodes into the same types.
+// body does not need to be complete JSON, so truncated bodies are redacted as well.
+func RedactBody(body []byte) []byte {
+	out := sensitiveFields.ReplaceAllFunc(body, func(field []byte) []byte {
+		m := sensitiveFields.FindSubmatch(field)
+		value := `"` + redacted + `"`
+		if m[3] != nil {
+			value = "0"
+		}
+		return append(append([]byte(nil), m[1]...), value...)
+	})
+	return sessionCookie.ReplaceAll(out, []byte("${1}"+redacted))
+}
@@ -9,6 +9,7 @@ package tenable
 import (
 	"context"
 	"fmt"
+	"sync"
 )
 
 // RepositoryService handles users for the Tenable instance / API.
@@ -19,28 +20,15 @@ type RepositoryService struct {
 }
 
 type Repository struct {
-	ID          interface{} `json:"id"`
-	Name        string      `json:"name,omitempty"`
-	Description string      `json:"description,omitempty"`
-	DataFormat  string      `json:"dataFormat,omitempty"`
-	UUID        string      `json:"uuid,omitempty"`
+	ID          ID     `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	DataFormat  string `json:"dataFormat,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
 }
 
-// Repository represents a Tenable user.
-type RepositoryResponse struct {
-	// "type": "regular",
-	Type string
-	// "response": {
-	Response []Repository `json:"response"`
-	// "error_code": 0,
-	ErrorCode int `json:"error_code"`
-	// "error_msg": "",
-	ErrorMsg string `json:"error_msg"`
-	// "warnings": [],
-	Warnings []string
-	// "timestamp": 1657818772
-	Timestamp int
-}
+// RepositoryResponse represents a Tenable repository list response.
+type RepositoryResponse = Envelope[[]Repository]
 
 // GetWithContext gets user info from Tenable using its Account Id
 //
@@ -53,15 +41,10 @@ func (s *RepositoryService) GetWithContext(ctx context.Context, requestType, fie
 	if len(fields) > 0 {
 		apiEndpoint = apiEndpoint + "&fields=" + fields
 	}
-	req, err := s.client.NewRequestWithContext(ctx, "GET", apiEndpoint, nil)
-	if err != nil {
-		return nil, nil, err
-	}
 
-	repoResp := new(RepositoryResponse)
-	resp, err := s.client.Do(req, repoResp)
+	repoResp, resp, err := get[[]Repository](ctx, s.client, apiEndpoint)
 	if err != nil {
-		return nil, resp, NewTenableError(resp, err)
+		return nil, resp, err
 	}
 	return repoResp.Response, resp, nil
 }
@@ -70,3 +53,24 @@ func (s *RepositoryService) GetWithContext(ctx context.Context, requestType, fie
 func (s *RepositoryService) Get(requestType, fields string) ([]Repository, *Response, error) {
 	return s.GetWithContext(context.Background(), requestType, fields)
 }
+
+// ResolverWithContext returns a QueryParser.Resolve function looking up repository names in the
+// repositories of Tenable. The repositories are fetched once, when the first name is resolved.
+func (s *RepositoryService) ResolverWithContext(ctx context.Context) func(filter, name string) (ID, error) {
+	var (
+		once    sync.Once
+		resolve func(filter, name string) (ID, error)
+		err     error
+	)
+	return func(filter, name string) (ID, error) {
+		once.Do(func() {
+			var repos []Repository
+			repos, _, err = s.GetWithContext(ctx, "All", "id,name")
+			resolve = RepositoryResolver(repos)
+		})
+		if err != nil {
+			return 0, fmt.Errorf("listing repositories: %w", err)
+		}
+		return resolve(filter, name)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how Client.Do retries requests which failed with a transient error.
+// A nil policy (the default) sends every request exactly once.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. It doubles with every further attempt.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the wait between two attempts, including waits requested by Retry-After.
+	MaxBackoff time.Duration
+
+	// MaxElapsed caps the total time spent on a request including all retries.
+	// Zero means no limit besides MaxAttempts and the request context.
+	MaxElapsed time.Duration
+
+	// ShouldRetry decides if an attempt is retried. Either resp or err is set.
+	// If nil, DefaultShouldRetry is used which only retries idempotent requests.
+	ShouldRetry func(req *http.Request, resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns a policy with up to 4 attempts over at most 2 minutes
+// and exponential backoff between 500ms and 30s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		MaxElapsed:  2 * time.Minute,
+	}
+}
+
+// DefaultShouldRetry retries idempotent requests which failed with a transient error.
+func DefaultShouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	return IsIdempotent(req) && IsTransient(resp, err)
+}
+
+// IsIdempotent reports whether req uses a method which can safely be repeated.
+// POST requests (like /rest/analysis queries) are not considered idempotent;
+// use a custom RetryPolicy.ShouldRetry to retry them.
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// IsTransient reports whether an attempt failed with a network error or a status code
+// (429, 502, 503, 504) that is likely to succeed when tried again.
+func IsTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+			errors.Is(err, syscall.EPIPE) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// SetRetryPolicy configures the retry behaviour of c. A nil policy disables retries.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.retryPolicy = p
+}
+
+// send performs req with the configured retry policy.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	p := c.retryPolicy
+	if p == nil || p.MaxAttempts < 2 {
+		return c.roundTrip(req, 0)
+	}
+	shouldRetry := p.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultShouldRetry
+	}
+
+	start := time.Now()
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := c.roundTrip(attemptReq, attempt-1)
+		if attempt >= p.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		// requests with a body can only be repeated if the body can be replayed
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait := p.backoff(attempt, resp)
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			return resp, err
+		}
+		if resp != nil {
+			// drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		attemptReq = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+	}
+}
+
+// backoff returns the wait before the next attempt: the Retry-After of resp if present,
+// otherwise an exponential backoff with jitter.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+		return wait
+	}
+
+	wait := p.MinBackoff << (attempt - 1)
+	if wait <= 0 || (p.MaxBackoff > 0 && wait > p.MaxBackoff) {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// wait somewhere between half and the full backoff so parallel clients spread out
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// retryAfter parses the Retry-After header of resp, given either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
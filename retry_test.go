@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestClient_Do_RetryIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+	testClient.SetRetryPolicy(testRetryPolicy())
+
+	attempts := 0
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"id":"1","username":"admin"},"error_code":0}`)
+	})
+
+	user, _, err := testClient.CurrentUser.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "admin" {
+		t.Errorf("Username: %s, want admin", user.Username)
+	}
+	if attempts != 3 {
+		t.Errorf("Attempts: %d, want 3", attempts)
+	}
+}
+
+func TestClient_Do_RetryGivesUp(t *testing.T) {
+	setup()
+	defer teardown()
+	testClient.SetRetryPolicy(testRetryPolicy())
+
+	attempts := 0
+	testMux.HandleFunc("/rest/currentUser", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, resp, err := testClient.CurrentUser.Get()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if resp == nil || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the last response to be returned, got %+v", resp)
+	}
+	if attempts != 3 {
+		t.Errorf("Attempts: %d, want 3", attempts)
+	}
+}
+
+func TestClient_Do_RetryPost(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var bodies []string
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"type":"regular","response":{"totalRecords":"0","results":[]},"error_code":0}`)
+	})
+
+	// POST is not idempotent and therefore not retried by default
+	testClient.SetRetryPolicy(testRetryPolicy())
+	if _, _, err := testClient.Analysis.Post(AnalysisBody{Type: "vuln"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Attempts: %d, want 1", len(bodies))
+	}
+
+	bodies = nil
+	p := testRetryPolicy()
+	p.ShouldRetry = func(req *http.Request, resp *http.Response, err error) bool {
+		analysis := req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/rest/analysis")
+		return (IsIdempotent(req) || analysis) && IsTransient(resp, err)
+	}
+	testClient.SetRetryPolicy(p)
+	if _, _, err := testClient.Analysis.Post(AnalysisBody{Type: "vuln"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("Attempts: %d, want 2", len(bodies))
+	}
+	if bodies[0] != bodies[1] || !strings.Contains(bodies[1], `"type":"vuln"`) {
+		t.Errorf("Expected the request body to be replayed, got %q", bodies)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		got := p.backoff(attempt+1, nil)
+		if got < max/2 || got > max {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", attempt+1, got, max/2, max)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"120"}}}
+	if got := p.backoff(1, resp); got != time.Second {
+		t.Errorf("Expected Retry-After to be capped at MaxBackoff, got %s", got)
+	}
+	resp.Header.Set("Retry-After", "0")
+	if got := p.backoff(3, resp); got != 0 {
+		t.Errorf("Expected Retry-After 0 to be honored, got %s", got)
+	}
+}
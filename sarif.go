@@ -0,0 +1,257 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SARIF 2.1.0 version and schema of the logs written by WriteSARIF.
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is a SARIF 2.1.0 log, limited to the properties used for analysis findings.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a run of a tool and its results.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the tool of a run.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component which produced the results, its rules are the plugins.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules,omitempty"`
+}
+
+// SARIFMessage is a plain text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFRule describes a plugin.
+type SARIFRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     *SARIFMessage          `json:"shortDescription,omitempty"`
+	FullDescription      *SARIFMessage          `json:"fullDescription,omitempty"`
+	Help                 *SARIFMessage          `json:"help,omitempty"`
+	DefaultConfiguration *SARIFConfiguration    `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFConfiguration is the default configuration of a rule.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFResult is a finding of a plugin on a host.
+type SARIFResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             SARIFMessage           `json:"message"`
+	Locations           []SARIFLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFLocation is the host and port of a finding.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation refers to the finding's service, e.g. "tcp://10.0.0.1:443".
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is the URI of a location.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation names the host of a finding.
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// SARIFFinding are the result types which can be exported as SARIF.
+type SARIFFinding interface {
+	Analysis | VulnDetail
+}
+
+// SARIFLevel returns the SARIF level of a severity: critical and high findings are errors,
+// medium ones warnings, low ones notes and informational ones none.
+func SARIFLevel(l SeverityLevel) string {
+	switch {
+	case l >= SeverityHigh:
+		return "error"
+	case l == SeverityMedium:
+		return "warning"
+	case l == SeverityLow:
+		return "note"
+	}
+	return "none"
+}
+
+// NewSARIF converts listvuln or vulndetails results to a SARIF log of a single run.
+// Each plugin becomes a rule, each result a SARIF result located at its host and port.
+func NewSARIF[T SARIFFinding](results []T) *SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:           "Tenable.sc",
+			InformationURI: "https://www.tenable.com/products/tenable-sc",
+		}},
+		Results: make([]SARIFResult, 0, len(results)),
+	}
+	rules := make(map[ID]int)
+	for _, r := range results {
+		var d VulnDetail
+		switch v := any(r).(type) {
+		case Analysis:
+			d.Analysis = v
+		case VulnDetail:
+			d = v
+		}
+		if d.Name == "" {
+			d.Name = d.PluginName
+		}
+		index, ok := rules[d.PluginID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[d.PluginID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule(d))
+		}
+		run.Results = append(run.Results, sarifResult(d, index))
+	}
+	return &SARIFLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []SARIFRun{run}}
+}
+
+// WriteSARIF writes results as SARIF log to w, see NewSARIF.
+func WriteSARIF[T SARIFFinding](w io.Writer, results []T) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewSARIF(results))
+}
+
+func sarifRule(d VulnDetail) SARIFRule {
+	rule := SARIFRule{
+		ID:                   strconv.FormatInt(int64(d.PluginID), 10),
+		Name:                 d.Name,
+		DefaultConfiguration: &SARIFConfiguration{Level: SARIFLevel(d.SeverityLevel())},
+		Properties:           map[string]interface{}{},
+	}
+	if short := firstNonEmpty(d.Synopsis, d.Name); short != "" {
+		rule.ShortDescription = &SARIFMessage{Text: short}
+	}
+	if d.Description != "" {
+		rule.FullDescription = &SARIFMessage{Text: d.Description}
+	}
+	if help := strings.TrimSpace(strings.Join(append([]string{d.Solution, ""}, d.SeeAlso...), "\n")); help != "" {
+		rule.Help = &SARIFMessage{Text: help}
+	}
+	tags := []string{"security"}
+	if d.Family.Name != "" {
+		tags = append(tags, d.Family.Name)
+	}
+	rule.Properties["tags"] = tags
+	// security-severity is the 0-10 score code scanning views rank rules by
+	for _, score := range []Float{d.CVSSV3BaseScore, d.CVSSV2BaseScore, d.VPRScore} {
+		if score > 0 {
+			rule.Properties["security-severity"] = strconv.FormatFloat(float64(score), 'f', 1, 64)
+			break
+		}
+	}
+	return rule
+}
+
+func sarifResult(d VulnDetail, ruleIndex int) SARIFResult {
+	res := SARIFResult{
+		RuleID:    strconv.FormatInt(int64(d.PluginID), 10),
+		RuleIndex: ruleIndex,
+		Level:     SARIFLevel(d.SeverityLevel()),
+		Message:   SARIFMessage{Text: firstNonEmpty(strings.TrimSpace(d.PluginText), d.Synopsis, d.Name, "Plugin "+strconv.FormatInt(int64(d.PluginID), 10))},
+		Locations: []SARIFLocation{sarifLocation(d.Analysis)},
+		PartialFingerprints: map[string]string{
+			"tenableFindingKey/v1": d.FindingKey().String(),
+		},
+		Properties: map[string]interface{}{},
+	}
+	if d.Severity.Name != "" {
+		res.Properties["severity"] = d.Severity.Name
+	}
+	if d.Repository.Name != "" {
+		res.Properties["repository"] = d.Repository.Name
+	}
+	for name, score := range map[string]Float{
+		"vprScore":        d.VPRScore,
+		"cvssV2BaseScore": d.CVSSV2BaseScore,
+		"cvssV3BaseScore": d.CVSSV3BaseScore,
+	} {
+		if score > 0 {
+			res.Properties[name] = float64(score)
+		}
+	}
+	if d.CVSSV3Vector != "" {
+		res.Properties["cvssV3Vector"] = d.CVSSV3Vector
+	}
+	if len(d.CVE) > 0 {
+		res.Properties["cve"] = []string(d.CVE)
+	}
+	if len(res.Properties) == 0 {
+		res.Properties = nil
+	}
+	return res
+}
+
+func sarifLocation(a Analysis) SARIFLocation {
+	host := firstNonEmpty(a.IP, a.DNSName, a.NetBiosName, a.UUID)
+	scheme := strings.ToLower(firstNonEmpty(a.Protocol, "tcp"))
+	u := url.URL{Scheme: scheme, Host: host}
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if a.Port > 0 {
+		u.Host = net.JoinHostPort(host, strconv.FormatInt(int64(a.Port), 10))
+	}
+	loc := SARIFLocation{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: u.String()}}}
+	if name := firstNonEmpty(a.DNSName, a.IP, a.NetBiosName); name != "" {
+		loc.LogicalLocations = []SARIFLogicalLocation{{Name: name, FullyQualifiedName: a.FindingKey().Host, Kind: "resource"}}
+	}
+	return loc
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
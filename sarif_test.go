@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// sarifSchema is the OASIS SARIF 2.1.0 schema (errata 01) in testdata.
+var sarifSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	return jsonschema.Compile("testdata/sarif-schema-2.1.0.json")
+})
+
+// validateSARIF validates data against the SARIF 2.1.0 schema and checks that the ruleIndex
+// of every result refers to its rule, which the schema cannot express.
+func validateSARIF(data []byte) error {
+	schema, err := sarifSchema()
+	if err != nil {
+		return err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if err := schema.Validate(v); err != nil {
+		return err
+	}
+
+	var log SARIFLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return err
+	}
+	for i, run := range log.Runs {
+		rules := run.Tool.Driver.Rules
+		for j, res := range run.Results {
+			if res.RuleID != "" && (res.RuleIndex < 0 || res.RuleIndex >= len(rules) || rules[res.RuleIndex].ID != res.RuleID) {
+				return fmt.Errorf("runs[%d].results[%d]: ruleIndex %d does not refer to rule %s", i, j, res.RuleIndex, res.RuleID)
+			}
+		}
+	}
+	return nil
+}
+
+func TestWriteSARIF(t *testing.T) {
+	tls := VulnDetail{
+		Analysis: Analysis{
+			PluginID: 42873, Name: "SSL Medium Strength Cipher Suites Supported", IP: "10.0.0.1", DNSName: "web.example.com",
+			Port: 443, Protocol: "TCP", Severity: Severity{ID: 3, Name: "High"}, VPRScore: 5.1,
+			Family: Family{Name: "General"}, Repository: Repository{ID: 1, Name: "Servers"},
+		},
+		Synopsis:        "The remote service supports the use of medium strength SSL ciphers.",
+		Solution:        "Reconfigure the affected application.",
+		PluginText:      "<plugin_output>Medium Strength Ciphers: DES-CBC3-SHA</plugin_output>",
+		CVSSV2BaseScore: 5.0,
+		CVSSV3BaseScore: 7.5,
+	}
+	other := tls
+	other.IP, other.DNSName = "fe80::1", ""
+	info := VulnDetail{Analysis: Analysis{PluginID: 19506, Name: "Nessus Scan Information", IP: "10.0.0.1", Protocol: "TCP"}}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, []VulnDetail{tls, info, other}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSARIF(buf.Bytes()); err != nil {
+		t.Fatalf("Invalid SARIF: %v\n%s", err, buf.String())
+	}
+
+	var log SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 3 {
+		t.Fatalf("Got %d rules and %d results, want 2 and 3", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ID != "42873" || rule.DefaultConfiguration.Level != "error" || rule.Properties["security-severity"] != "7.5" {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	res := run.Results[0]
+	if res.Level != "error" || res.Message.Text != tls.PluginText || res.Properties["vprScore"] != 5.1 || res.Properties["cvssV3BaseScore"] != 7.5 {
+		t.Errorf("Unexpected result %+v", res)
+	}
+	if uri := res.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "tcp://10.0.0.1:443" {
+		t.Errorf("URI %q, want tcp://10.0.0.1:443", uri)
+	}
+	if uri := run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "tcp://[fe80::1]:443" || run.Results[2].RuleIndex != 0 {
+		t.Errorf("Unexpected IPv6 result %+v", run.Results[2])
+	}
+	if r := run.Results[1]; r.Level != "none" || r.Message.Text != info.Name || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "tcp://10.0.0.1" {
+		t.Errorf("Unexpected informational result %+v", r)
+	}
+}
+
+func TestNewSARIF_Analysis(t *testing.T) {
+	log := NewSARIF([]Analysis{{PluginID: 1, Severity: Severity{ID: 2}, IP: "10.0.0.1", Port: 53, Protocol: "UDP"}})
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSARIF(data); err != nil {
+		t.Fatalf("Invalid SARIF: %v\n%s", err, data)
+	}
+	res := log.Runs[0].Results[0]
+	if res.Level != "warning" || res.Message.Text != "Plugin 1" || res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "udp://10.0.0.1:53" {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	// the schema rejects invalid logs
+	if err := validateSARIF([]byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"x"}},"results":[{"message":{"text":"x"},"level":"fatal"}]}]}`)); err == nil {
+		t.Error("Expected an invalid level error")
+	}
+	if err := validateSARIF([]byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{}}}]}`)); err == nil {
+		t.Error("Expected a missing driver name error")
+	}
+	if err := validateSARIF([]byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"x"}},"results":[{"message":{"text":7}}]}]}`)); err == nil {
+		t.Error("Expected a message type error")
+	}
+}
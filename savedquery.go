@@ -0,0 +1,302 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// QueryService handles the saved queries of the Tenable instance / API.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Query.htm
+type QueryService struct {
+	client *Client
+}
+
+// SavedQuery is a query saved in Tenable.sc.
+type SavedQuery struct {
+	ID           ID               `json:"id"`
+	Name         string           `json:"name,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	Type         string           `json:"type,omitempty"`
+	Tool         string           `json:"tool,omitempty"`
+	Tags         string           `json:"tags,omitempty"`
+	Context      string           `json:"context,omitempty"`
+	StartOffset  Int              `json:"startOffset,omitempty"`
+	EndOffset    Int              `json:"endOffset,omitempty"`
+	SortField    string           `json:"sortField,omitempty"`
+	SortDir      SortDirection    `json:"sortDir,omitempty"`
+	Filters      []AnalysisFilter `json:"filters,omitempty"`
+	CreatedTime  Epoch            `json:"createdTime,omitempty"`
+	ModifiedTime Epoch            `json:"modifiedTime,omitempty"`
+	CanUse       Bool             `json:"canUse,omitempty"`
+	CanManage    Bool             `json:"canManage,omitempty"`
+	Owner        User             `json:"owner,omitempty"`
+}
+
+// SavedQueryList is the response of /rest/query: the queries the user may use and those they may manage.
+type SavedQueryList struct {
+	Usable     []SavedQuery `json:"usable"`
+	Manageable []SavedQuery `json:"manageable"`
+}
+
+// savedQueryFields are the fields requested for running saved queries.
+const savedQueryFields = "id,name,description,type,tool,tags,context,startOffset,endOffset,sortField,sortDir,filters,createdTime,modifiedTime,canUse,canManage,owner"
+
+// ListWithContext lists the saved queries, restricted to the given comma separated fields if not empty.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Query.htm
+func (s *QueryService) ListWithContext(ctx context.Context, fields string) (*SavedQueryList, *Response, error) {
+	apiEndpoint := "/rest/query"
+	if len(fields) > 0 {
+		apiEndpoint = apiEndpoint + "?fields=" + url.QueryEscape(fields)
+	}
+	list, resp, err := get[SavedQueryList](ctx, s.client, apiEndpoint)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &list.Response, resp, nil
+}
+
+// List wraps ListWithContext using the background context.
+func (s *QueryService) List(fields string) (*SavedQueryList, *Response, error) {
+	return s.ListWithContext(context.Background(), fields)
+}
+
+// GetWithContext gets the saved query with the given ID.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Query.htm
+func (s *QueryService) GetWithContext(ctx context.Context, id ID) (*SavedQuery, *Response, error) {
+	apiEndpoint := fmt.Sprintf("/rest/query/%d?fields=%s", id, url.QueryEscape(savedQueryFields))
+	query, resp, err := get[SavedQuery](ctx, s.client, apiEndpoint)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &query.Response, resp, nil
+}
+
+// Get wraps GetWithContext using the background context.
+func (s *QueryService) Get(id ID) (*SavedQuery, *Response, error) {
+	return s.GetWithContext(context.Background(), id)
+}
+
+// FindByNameWithContext returns the usable saved query with the given name.
+// It fails with an error matching ErrNotFound if there is none, and with another error if the name is ambiguous.
+func (s *QueryService) FindByNameWithContext(ctx context.Context, name string) (*SavedQuery, *Response, error) {
+	list, resp, err := s.ListWithContext(ctx, "id,name,type,tool")
+	if err != nil {
+		return nil, resp, err
+	}
+	var found *SavedQuery
+	for i, q := range list.Usable {
+		if q.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, resp, fmt.Errorf("tenable: saved query name %q is ambiguous (IDs %d and %d)", name, found.ID, q.ID)
+		}
+		found = &list.Usable[i]
+	}
+	if found == nil {
+		return nil, resp, fmt.Errorf("tenable: no saved query named %q: %w", name, ErrNotFound)
+	}
+	return found, resp, nil
+}
+
+// FindByName wraps FindByNameWithContext using the background context.
+func (s *QueryService) FindByName(name string) (*SavedQuery, *Response, error) {
+	return s.FindByNameWithContext(context.Background(), name)
+}
+
+// SavedQueryOptions override parts of a saved query when running it.
+type SavedQueryOptions struct {
+	// StartOffset and EndOffset replace the offsets of the saved query, if EndOffset is set
+	StartOffset int64
+	EndOffset   int64
+	// SortField and SortDir replace the sorting of the saved query, if SortField is set
+	SortField string
+	SortDir   SortDirection
+	// Filters are added to the filters of the saved query
+	Filters []AnalysisFilter
+	// SourceType is SourceCumulative if empty
+	SourceType SourceType
+}
+
+// savedQueryRef references a saved query by ID, along with the overridden fields.
+type savedQueryRef struct {
+	ID          ID               `json:"id"`
+	Type        string           `json:"type"`
+	Tool        string           `json:"tool"`
+	SourceType  SourceType       `json:"sourceType"`
+	StartOffset int64            `json:"startOffset"`
+	EndOffset   int64            `json:"endOffset"`
+	Filters     []AnalysisFilter `json:"filters,omitempty"`
+	SortField   string           `json:"sortField,omitempty"`
+	SortDir     SortDirection    `json:"sortDir,omitempty"`
+}
+
+// savedQueryBody is the analysis request of a saved query.
+type savedQueryBody struct {
+	Type       string        `json:"type"`
+	SourceType SourceType    `json:"sourceType"`
+	Query      savedQueryRef `json:"query"`
+	SortField  string        `json:"sortField,omitempty"`
+	SortDir    SortDirection `json:"sortDir,omitempty"`
+}
+
+// newSavedQueryBody returns the request running saved with the overrides of opts.
+// The options are validated like those of an AnalysisBody, the sorting of saved is sent unchanged.
+func newSavedQueryBody(saved *SavedQuery, opts *SavedQueryOptions) (savedQueryBody, error) {
+	if opts == nil {
+		opts = &SavedQueryOptions{}
+	}
+	body := AnalysisBody{
+		Type:       saved.Type,
+		SourceType: string(opts.SourceType),
+		Query: AnalysisQuery{
+			Tool:        saved.Tool,
+			StartOffset: int64(saved.StartOffset),
+			EndOffset:   int64(saved.EndOffset),
+		},
+	}
+	if opts.EndOffset > 0 {
+		body.Query.StartOffset, body.Query.EndOffset = opts.StartOffset, opts.EndOffset
+	}
+	if body.Query.EndOffset <= body.Query.StartOffset {
+		body.Query.EndOffset = body.Query.StartOffset + DefaultPageSize
+	}
+	if opts.SortField != "" {
+		body.SortField, body.SortDir = opts.SortField, opts.SortDir
+	}
+	body, err := body.normalize()
+	if err != nil {
+		return savedQueryBody{}, fmt.Errorf("tenable: saved query %d: %w", saved.ID, err)
+	}
+	if opts.SortField == "" {
+		// the server accepted the sorting of the saved query, it may sort by fields the result types lack
+		body.SortField, body.SortDir = saved.SortField, saved.SortDir
+	}
+
+	ref := savedQueryRef{
+		ID:          saved.ID,
+		Type:        body.Type,
+		Tool:        body.Query.Tool,
+		SourceType:  SourceType(body.SourceType),
+		StartOffset: body.Query.StartOffset,
+		EndOffset:   body.Query.EndOffset,
+		SortField:   body.SortField,
+		SortDir:     body.SortDir,
+	}
+	if len(opts.Filters) > 0 {
+		// filters sent along with the ID replace the saved ones
+		ref.Filters = append(append([]AnalysisFilter(nil), saved.Filters...), opts.Filters...)
+	}
+	return savedQueryBody{
+		Type:       body.Type,
+		SourceType: SourceType(body.SourceType),
+		Query:      ref,
+		SortField:  body.SortField,
+		SortDir:    body.SortDir,
+	}, nil
+}
+
+// SavedQueryResult is the result of running a saved query. The type of Results depends on Tool,
+// e.g. the results of a sumip query are IPSummary values:
+//
+//	for _, r := range result.Results {
+//		if host, ok := r.(tenable.IPSummary); ok { ... }
+//	}
+type SavedQueryResult struct {
+	Query *SavedQuery
+	Tool  Tool
+	ResultSet[ToolResult]
+}
+
+// RunSavedQueryWithContext runs the saved vulnerability query with the given ID, applying the overrides of opts.
+// The results are decoded into the result type of the saved query's tool, see SavedQueryResult.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) RunSavedQueryWithContext(ctx context.Context, id ID, opts *SavedQueryOptions) (*SavedQueryResult, *Response, error) {
+	saved, resp, err := s.client.Query.GetWithContext(ctx, id)
+	if err != nil {
+		return nil, resp, err
+	}
+	return s.runSavedQuery(ctx, saved, opts)
+}
+
+// RunSavedQuery wraps RunSavedQueryWithContext using the background context.
+func (s *AnalysisService) RunSavedQuery(id ID, opts *SavedQueryOptions) (*SavedQueryResult, *Response, error) {
+	return s.RunSavedQueryWithContext(context.Background(), id, opts)
+}
+
+// RunSavedQueryByNameWithContext looks up the saved query by name and runs it like RunSavedQueryWithContext.
+func (s *AnalysisService) RunSavedQueryByNameWithContext(ctx context.Context, name string, opts *SavedQueryOptions) (*SavedQueryResult, *Response, error) {
+	found, resp, err := s.client.Query.FindByNameWithContext(ctx, name)
+	if err != nil {
+		return nil, resp, err
+	}
+	return s.RunSavedQueryWithContext(ctx, found.ID, opts)
+}
+
+// RunSavedQueryByName wraps RunSavedQueryByNameWithContext using the background context.
+func (s *AnalysisService) RunSavedQueryByName(name string, opts *SavedQueryOptions) (*SavedQueryResult, *Response, error) {
+	return s.RunSavedQueryByNameWithContext(context.Background(), name, opts)
+}
+
+func (s *AnalysisService) runSavedQuery(ctx context.Context, saved *SavedQuery, opts *SavedQueryOptions) (*SavedQueryResult, *Response, error) {
+	tool := Tool(saved.Tool)
+	prototype, ok := vulnTools[tool]
+	if (saved.Type != "" && saved.Type != "vuln") || !ok {
+		return nil, nil, fmt.Errorf("tenable: saved query %d has the unsupported tool %s %q", saved.ID, saved.Type, saved.Tool)
+	}
+	body, err := newSavedQueryBody(saved, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, resp, err := post[ResultSet[json.RawMessage]](ctx, s.client, "/rest/analysis", body)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	result := &SavedQueryResult{Query: saved, Tool: tool}
+	rs := page.Response
+	result.TotalRecords, result.ReturnedRecords = rs.TotalRecords, rs.ReturnedRecords
+	result.StartOffset, result.EndOffset = rs.StartOffset, rs.EndOffset
+	result.MatchingDataElementCount = rs.MatchingDataElementCount
+	result.Results = make([]ToolResult, len(rs.Results))
+	resultType := reflect.TypeOf(prototype)
+	for i, raw := range rs.Results {
+		v := reflect.New(resultType)
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, resp, err
+		}
+		result.Results[i] = v.Elem().Interface().(ToolResult)
+	}
+	return result, resp, nil
+}
+
+// RunSavedQueryAs runs the saved query with the given ID like AnalysisService.RunSavedQueryWithContext,
+// decoding the results as T. It fails if the tool of the saved query does not produce T.
+func RunSavedQueryAs[T ToolResult](ctx context.Context, s *AnalysisService, id ID, opts *SavedQueryOptions) (*Envelope[ResultSet[T]], *Response, error) {
+	saved, resp, err := s.client.Query.GetWithContext(ctx, id)
+	if err != nil {
+		return nil, resp, err
+	}
+	var zero T
+	if Tool(saved.Tool) != zero.Tool() {
+		return nil, resp, fmt.Errorf("tenable: saved query %d uses the tool %q, not %q of %T", id, saved.Tool, zero.Tool(), zero)
+	}
+	body, err := newSavedQueryBody(saved, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", body)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func testSavedQueries(t *testing.T, check func(body map[string]interface{})) {
+	testMux.HandleFunc("/rest/query", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`{"type":"regular","response":{"usable":[
+			{"id":"12","name":"Critical hosts","type":"vuln","tool":"sumip"},
+			{"id":"13","name":"Dup","type":"vuln","tool":"listvuln"},
+			{"id":"14","name":"Dup","type":"vuln","tool":"listvuln"}],"manageable":[]},"error_code":0}`))
+	})
+	testMux.HandleFunc("/rest/query/12", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		w.Write([]byte(`{"type":"regular","response":{"id":"12","name":"Critical hosts","type":"vuln","tool":"sumip",
+			"startOffset":"0","endOffset":"25","sortField":"score","sortDir":"DESC",
+			"filters":[{"filterName":"severity","operator":"=","value":"4"}]},"error_code":0}`))
+	})
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		check(body)
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"30","startOffset":"0","endOffset":"25","results":[
+			{"ip":"10.0.0.1","score":"40","severityCritical":"4"},{"ip":"10.0.0.2","score":"10","severityCritical":"1"}]},"error_code":0}`))
+	})
+}
+
+func TestAnalysisService_RunSavedQuery(t *testing.T) {
+	setup()
+	defer teardown()
+	testSavedQueries(t, func(body map[string]interface{}) {
+		query := body["query"].(map[string]interface{})
+		if query["id"] != "12" || query["tool"] != "sumip" || query["endOffset"] != 25.0 || body["sortField"] != "score" || body["sortDir"] != "DESC" {
+			t.Errorf("Unexpected body %v", body)
+		}
+		if _, ok := query["filters"]; ok {
+			t.Errorf("Expected the saved filters to be used, got %v", query["filters"])
+		}
+	})
+
+	result, resp, err := testClient.Analysis.RunSavedQueryByName("Critical hosts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tool != ToolSumIP || result.TotalRecords != 30 || len(result.Results) != 2 || resp.Total != 30 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	host, ok := result.Results[0].(IPSummary)
+	if !ok || host.IP != "10.0.0.1" || host.SeverityCritical != 4 {
+		t.Errorf("Unexpected first result %#v", result.Results[0])
+	}
+
+	if _, _, err := testClient.Analysis.RunSavedQueryByName("Dup", nil); err == nil {
+		t.Error("Expected an error for an ambiguous name")
+	}
+	if _, _, err := testClient.Analysis.RunSavedQueryByName("Missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRunSavedQueryAs_Overrides(t *testing.T) {
+	setup()
+	defer teardown()
+	testSavedQueries(t, func(body map[string]interface{}) {
+		query := body["query"].(map[string]interface{})
+		filters, _ := json.Marshal(query["filters"])
+		if query["startOffset"] != 100.0 || query["endOffset"] != 200.0 || body["sortField"] != "ip" || body["sortDir"] != "ASC" {
+			t.Errorf("Unexpected overrides %v", body)
+		}
+		want := `[{"filterName":"severity","id":"","isPredefined":false,"operator":"=","type":"","value":"4"},` +
+			`{"filterName":"exploitAvailable","id":"exploitAvailable","isPredefined":true,"operator":"=","type":"vuln","value":"true"}]`
+		if string(filters) != want {
+			t.Errorf("Filters %s\nwant %s", filters, want)
+		}
+	})
+
+	exploitable, _ := ExploitAvailableFilter(true)
+	opts := &SavedQueryOptions{StartOffset: 100, EndOffset: 200, SortField: "ip", SortDir: SortAsc, Filters: []AnalysisFilter{exploitable}}
+	hosts, _, err := RunSavedQueryAs[IPSummary](context.Background(), testClient.Analysis, 12, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts.Response.Results) != 2 || hosts.Response.Results[1].IP != "10.0.0.2" {
+		t.Errorf("Unexpected results %+v", hosts.Response.Results)
+	}
+
+	if _, _, err := RunSavedQueryAs[Analysis](context.Background(), testClient.Analysis, 12, nil); err == nil {
+		t.Error("Expected an error for a mismatching result type")
+	}
+	if _, _, err := RunSavedQueryAs[IPSummary](context.Background(), testClient.Analysis, 12, &SavedQueryOptions{SortField: "pluginID"}); err == nil {
+		t.Error("Expected an error for a sort field of another tool")
+	}
+}
+
+func TestAnalysisService_RunSavedQuery_ServerSort(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/query/15", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"regular","response":{"id":"15","name":"By repository","type":"vuln","tool":"sumip",
+			"startOffset":"0","endOffset":"25","sortField":"repositoryID","sortDir":"desc"},"error_code":0}`))
+	})
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := body["query"].(map[string]interface{})
+		if body["sortField"] != "repositoryID" || body["sortDir"] != "desc" || query["sortField"] != "repositoryID" {
+			t.Errorf("Expected the saved sorting unchanged, got %v", body)
+		}
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"0","results":[]},"error_code":0}`))
+	})
+
+	// the result types lack repositoryID, but the server sorts by it
+	if _, _, err := testClient.Analysis.RunSavedQuery(15, nil); err != nil {
+		t.Fatal(err)
+	}
+}
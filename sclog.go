@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SCLogTool is the analysis tool of an audit log query.
+type SCLogTool string
+
+// Analysis tools of audit log queries.
+const (
+	SCLogToolSCLog SCLogTool = "scLog"
+)
+
+// SCLogResult is implemented by the result types of the audit log tools.
+type SCLogResult interface {
+	SCLogTool() SCLogTool
+}
+
+// SCLogBody is a query of the Tenable.sc audit log (scLog analysis). The tool is set from the result type.
+type SCLogBody struct {
+	// Date is the month of the log as "YYYYMM", or "all" (the default)
+	Date        string
+	StartOffset int64
+	EndOffset   int64
+	Filters     []AnalysisFilter
+}
+
+// SCLogMonth returns the SCLogBody date of the month of t.
+func SCLogMonth(t time.Time) string {
+	return t.Format("200601")
+}
+
+// SCLogEntry is an entry of the Tenable.sc audit log.
+type SCLogEntry struct {
+	Date         Epoch        `json:"date"`
+	Module       string       `json:"module,omitempty"`
+	Severity     Severity     `json:"severity,omitempty"`
+	Message      string       `json:"message,omitempty"`
+	RawLog       string       `json:"rawLog,omitempty"`
+	Initiator    User         `json:"initiator,omitempty"`
+	Organization Organization `json:"organization,omitempty"`
+}
+
+// SCLogTool returns SCLogToolSCLog.
+func (SCLogEntry) SCLogTool() SCLogTool { return SCLogToolSCLog }
+
+// QuerySCLog queries the Tenable.sc audit log with the tool of T and decodes the results as T, e.g.
+//
+//	entries, _, err := tenable.QuerySCLog[tenable.SCLogEntry](ctx, client.Analysis, tenable.SCLogBody{Date: "201903"})
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func QuerySCLog[T SCLogResult](ctx context.Context, s *AnalysisService, body SCLogBody) (*Envelope[ResultSet[T]], *Response, error) {
+	var zero T
+	date := body.Date
+	if date == "" {
+		date = "all"
+	}
+	if _, err := time.Parse("200601", date); err != nil && date != "all" {
+		return nil, nil, fmt.Errorf("tenable: invalid scLog date %q, use YYYYMM or all", body.Date)
+	}
+	req := newTypedBody(AnalysisSCLog, "", string(zero.SCLogTool()), body.StartOffset, body.EndOffset, body.Filters)
+	req.Date = date
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", req)
+}
@@ -0,0 +1,40 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQuerySCLog(t *testing.T) {
+	setup()
+	defer teardown()
+	testTypedAnalysis(t, `{"totalRecords":"1","results":[{"date":"1553525692","module":"auth","severity":{"id":"1","name":"Warning"},
+		"message":"Login failed for user 'admin'.","initiator":{"id":"0","username":"admin"},"organization":{"id":"1","name":"Org"}}]}`,
+		func(body map[string]interface{}) {
+			query := body["query"].(map[string]interface{})
+			if body["type"] != "scLog" || body["date"] != "201903" || query["tool"] != "scLog" {
+				t.Errorf("Unexpected body %v", body)
+			}
+		})
+
+	month := SCLogMonth(time.Date(2019, 3, 25, 0, 0, 0, 0, time.UTC))
+	entries, _, err := QuerySCLog[SCLogEntry](context.Background(), testClient.Analysis, SCLogBody{Date: month, EndOffset: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := entries.Response.Results
+	if len(r) != 1 || r[0].Module != "auth" || r[0].Initiator.Username != "admin" || r[0].Organization.Name != "Org" || r[0].Date.Time().Unix() != 1553525692 {
+		t.Errorf("Unexpected results %+v", r)
+	}
+
+	if _, _, err := QuerySCLog[SCLogEntry](context.Background(), testClient.Analysis, SCLogBody{Date: "March"}); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IBM/go-tenable/cvss"
+)
+
+// CVSSVector returns the most recent CVSS vector of d, v4.0 before v3.x before v2, or nil if d has none.
+func (d VulnDetail) CVSSVector() (cvss.Vector, error) {
+	switch {
+	case d.CVSSV4Vector != "":
+		return cvss.ParseV4(d.CVSSV4Vector)
+	case d.CVSSV3Vector != "":
+		return cvss.ParseV3(d.CVSSV3Vector)
+	case d.CVSSV2Vector != "":
+		return cvss.ParseV2(d.CVSSV2Vector)
+	}
+	return nil, nil
+}
+
+// ScoredVuln is a vulndetails result with the CVSS scores computed from its vector.
+type ScoredVuln struct {
+	VulnDetail
+	// Vector is the CVSS vector of the finding with the environment of its asset applied, nil if it has none
+	Vector             cvss.Vector
+	BaseScore          float64
+	TemporalScore      float64
+	EnvironmentalScore float64
+	// Err is the error parsing the vector or applying the environment, the scores are zero if set
+	Err error
+}
+
+// ScoreCVSS computes the CVSS scores of results from their vectors instead of relying on the scores
+// computed by Tenable. env returns the environmental overrides of the asset of a finding, e.g. looked up
+// by IP address or repository, it may be nil. Results without CVSS vector have zero scores.
+// A malformed vector or environment does not stop the scoring of the other results, it is recorded in the
+// Err field of its finding and all such errors are returned joined along with the results.
+func ScoreCVSS(results []VulnDetail, env func(Analysis) cvss.Environment) ([]ScoredVuln, error) {
+	scored := make([]ScoredVuln, len(results))
+	var errs []error
+	for i, d := range results {
+		scored[i].VulnDetail = d
+		if err := scored[i].score(env); err != nil {
+			scored[i].Err = err
+			errs = append(errs, err)
+		}
+	}
+	return scored, errors.Join(errs...)
+}
+
+func (s *ScoredVuln) score(env func(Analysis) cvss.Environment) error {
+	v, err := s.CVSSVector()
+	if err != nil {
+		return fmt.Errorf("tenable: plugin %d on %s: %w", s.PluginID, s.FindingKey().Host, err)
+	}
+	if v == nil {
+		return nil
+	}
+	if env != nil {
+		if v, err = env(s.Analysis).Apply(v); err != nil {
+			return fmt.Errorf("tenable: environment of %s: %w", s.FindingKey().Host, err)
+		}
+	}
+	s.Vector = v
+	s.BaseScore = v.BaseScore()
+	s.TemporalScore = v.TemporalScore()
+	s.EnvironmentalScore = v.EnvironmentalScore()
+	return nil
+}
+
+// SortByEnvironmentalScore sorts scored findings by their environmental score, highest first.
+// Findings of equal score are ordered by VPR.
+func SortByEnvironmentalScore(scored []ScoredVuln) {
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].EnvironmentalScore != scored[j].EnvironmentalScore {
+			return scored[i].EnvironmentalScore > scored[j].EnvironmentalScore
+		}
+		return scored[i].VPRScore > scored[j].VPRScore
+	})
+}
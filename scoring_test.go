@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/go-tenable/cvss"
+)
+
+func TestScoreCVSS(t *testing.T) {
+	results := []VulnDetail{
+		{Analysis: Analysis{PluginID: 1, IP: "10.0.0.1", VPRScore: 5.9}, CVSSV2Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P", CVSSV3Vector: "AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:U/RL:O/RC:C"},
+		{Analysis: Analysis{PluginID: 2, IP: "10.0.0.2", VPRScore: 6.7}, CVSSV3Vector: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", CVSSV4Vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+		{Analysis: Analysis{PluginID: 3, IP: "10.0.0.1"}, CVSSV2Vector: "CVSS2#AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+		{Analysis: Analysis{PluginID: 4, IP: "10.0.0.1"}},
+	}
+	// 10.0.0.1 is an internal host
+	env := func(a Analysis) cvss.Environment {
+		if a.IP == "10.0.0.1" {
+			return cvss.Environment{"MAV": "L", "CDP": "N"}
+		}
+		return nil
+	}
+	scored, err := ScoreCVSS(results, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		version                       string
+		base, temporal, environmental float64
+	}{
+		{"3.1", 9.8, 8.5, 7.3},
+		{"4.0", 8.7, 8.7, 8.7},
+		{"2.0", 7.5, 7.5, 7.5},
+		{"", 0, 0, 0},
+	}
+	for i, w := range want {
+		s := scored[i]
+		if s.Vector == nil && w.version != "" || s.Vector != nil && s.Vector.Version() != w.version {
+			t.Errorf("%d: unexpected vector %v", i, s.Vector)
+		}
+		if s.BaseScore != w.base || s.TemporalScore != w.temporal || s.EnvironmentalScore != w.environmental {
+			t.Errorf("%d: scores %v %v %v, want %v %v %v", i, s.BaseScore, s.TemporalScore, s.EnvironmentalScore, w.base, w.temporal, w.environmental)
+		}
+	}
+	if scored[0].Vector.Get("MAV") != "L" || scored[0].CVSSV3Vector != results[0].CVSSV3Vector {
+		t.Errorf("Expected the environment on the vector only, got %s", scored[0].Vector)
+	}
+
+	SortByEnvironmentalScore(scored)
+	for i, plugin := range []ID{2, 3, 1, 4} {
+		if scored[i].PluginID != plugin {
+			t.Errorf("scored[%d] is plugin %d, want %d", i, scored[i].PluginID, plugin)
+		}
+	}
+
+	results[0].CVSSV3Vector = "AV:N/AC:L"
+	scored, err = ScoreCVSS(results, nil)
+	if !errors.Is(err, cvss.ErrInvalidVector) {
+		t.Errorf("Expected an invalid vector error, got %v", err)
+	}
+	if len(scored) != len(results) || !errors.Is(scored[0].Err, cvss.ErrInvalidVector) || scored[0].Vector != nil {
+		t.Errorf("Expected the error on the first finding only, got %+v", scored[0])
+	}
+	if scored[1].Err != nil || scored[1].BaseScore != 8.7 {
+		t.Errorf("Expected the other findings scored, got %+v", scored[1])
+	}
+}
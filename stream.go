@@ -0,0 +1,186 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamWithContext posts an analysis query and calls fn for every result as soon as it is decoded,
+// so large result sets (e.g. 50k vulndetails rows) are never held in memory at once.
+// The returned AnalysisResponse carries totalRecords and the other paging fields, its Results are empty.
+// Envelope errors are reported as *APIError after the stream was read. If fn returns an error,
+// streaming stops and that error is returned.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) StreamWithContext(ctx context.Context, body interface{}, fn func(Analysis) error) (*AnalysisResponse, *Response, error) {
+	return stream(ctx, s.client, "/rest/analysis", body, fn)
+}
+
+// Stream wraps StreamWithContext using the background context.
+func (s *AnalysisService) Stream(body interface{}, fn func(Analysis) error) (*AnalysisResponse, *Response, error) {
+	return s.StreamWithContext(context.Background(), body, fn)
+}
+
+// stream posts body to endpoint and decodes the "results" of the response envelope one by one.
+func stream[T any](ctx context.Context, c *Client, endpoint string, body interface{}, fn func(T) error) (*Envelope[ResultSet[T]], *Response, error) {
+	req, err := c.NewRequestWithContext(ctx, "POST", endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.doStream(req)
+	if err != nil {
+		return nil, resp, NewTenableError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	envelope, err := decodeStream(resp.Body, fn)
+	if err != nil {
+		return nil, resp, err
+	}
+	if envelope.ErrorCode != 0 {
+		return nil, resp, &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     req.Method,
+			Endpoint:   req.URL.Path,
+			Type:       envelope.Type,
+			Code:       envelope.ErrorCode,
+			Message:    envelope.ErrorMsg,
+			Warnings:   envelope.Warnings,
+			Timestamp:  envelope.Timestamp,
+		}
+	}
+	resp.populatePageValues(envelope)
+	return envelope, resp, nil
+}
+
+// decodeStream walks the response envelope with a token based decoder,
+// calling fn for each element of response.results.
+func decodeStream[T any](r io.Reader, fn func(T) error) (*Envelope[ResultSet[T]], error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	envelope := new(Envelope[ResultSet[T]])
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "response":
+			err = decodeResultSetStream(dec, &envelope.Response, fn)
+		case "type":
+			err = dec.Decode(&envelope.Type)
+		case "error_code":
+			err = dec.Decode(&envelope.ErrorCode)
+		case "error_msg":
+			err = dec.Decode(&envelope.ErrorMsg)
+		case "warnings":
+			err = dec.Decode(&envelope.Warnings)
+		case "timestamp":
+			err = dec.Decode(&envelope.Timestamp)
+		default:
+			err = dec.Decode(new(json.RawMessage))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// decodeResultSetStream decodes the paging fields of the response into rs and passes the results to fn.
+// Responses which are no object (e.g. the empty string of failed requests) are skipped.
+func decodeResultSetStream[T any](dec *json.Decoder, rs *ResultSet[T], fn func(T) error) error {
+	var raw json.RawMessage
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+	case json.Delim('['):
+		// not a result set, skip the array
+		for dec.More() {
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+		}
+		return expectDelim(dec, ']')
+	default:
+		return nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := key.(string)
+		if name != "results" {
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			fields[name] = append(json.RawMessage(nil), raw...)
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok == nil {
+			// "results": null
+			if err != nil {
+				return err
+			}
+			continue
+		} else if tok != json.Delim('[') {
+			return fmt.Errorf("tenable: unexpected %v for results", tok)
+		}
+		for dec.More() {
+			var item T
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(meta, rs)
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("tenable: unexpected %v in response, want %v", tok, delim)
+	}
+	return nil
+}
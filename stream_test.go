@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnalysisService_Stream(t *testing.T) {
+	setup()
+	defer teardown()
+
+	raw, err := os.ReadFile("./mocks/analysis_get.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		w.Write(raw)
+	})
+
+	var results []Analysis
+	analysis, resp, err := testClient.Analysis.Stream(AnalysisBody{}, func(a Analysis) error {
+		results = append(results, a)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status code: %d", resp.StatusCode)
+	}
+	if len(results) != 1 || results[0].PluginID != 119500 || results[0].Repository.Name != "repo1" {
+		t.Errorf("Unexpected results %+v", results)
+	}
+	if analysis.Response.TotalRecords != 1 || analysis.Response.EndOffset != 50 || analysis.Response.MatchingDataElementCount != -1 {
+		t.Errorf("Unexpected result set %+v", analysis.Response)
+	}
+	if analysis.Type != "regular" || analysis.Timestamp != 1553525692 || len(analysis.Response.Results) != 0 {
+		t.Errorf("Unexpected envelope %+v", analysis)
+	}
+}
+
+func TestAnalysisService_StreamErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body string
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+	results := `{"type":"regular","response":{"totalRecords":"3","results":[{"pluginID":"1"},{"pluginID":"2"},{"pluginID":"3"}]},"error_code":0}`
+
+	// the error_code follows the response, so it is reported after streaming
+	body = `{"type":"regular","response":"","error_code":146,"error_msg":"Invalid parameters.","warnings":[],"timestamp":1}`
+	_, _, err := testClient.Analysis.Stream(AnalysisBody{}, func(Analysis) error { return nil })
+	if !errors.Is(err, &APIError{Code: 146}) {
+		t.Errorf("Expected error_code 146, got %v", err)
+	}
+
+	// the callback stops the stream
+	body = results
+	stop := errors.New("stop")
+	seen := 0
+	_, _, err = testClient.Analysis.Stream(AnalysisBody{}, func(Analysis) error {
+		seen++
+		if seen == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || seen != 2 {
+		t.Errorf("Expected the stream to stop after 2 results, got %d (%v)", seen, err)
+	}
+
+	// a truncated stream is an error
+	body = results[:strings.Index(results, `{"pluginID":"3"}`)+5]
+	seen = 0
+	_, _, err = testClient.Analysis.Stream(AnalysisBody{}, func(Analysis) error { seen++; return nil })
+	if err == nil || seen != 2 {
+		t.Errorf("Expected an error after 2 results, got %d (%v)", seen, err)
+	}
+}
@@ -12,10 +12,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"reflect"
-	"strconv"
 	"strings"
 
 	"github.com/google/go-querystring/query"
@@ -36,13 +36,24 @@ type Client struct {
 	baseURL *url.URL
 
 	// Session storage if the user authenticates with a Session cookie
-	//	session *Session
+	session *Session
+
+	// Retry policy for failed requests, nil disables retries
+	retryPolicy *RetryPolicy
+
+	// Optional settings configured through New
+	userAgent     string
+	limiter       *rateLimiter
+	logger        *slog.Logger
+	beforeRequest []BeforeRequestHook
+	afterResponse []AfterResponseHook
 
 	// Services used for talking to different parts of the Tenable API.
 	Analysis       *AnalysisService
 	Authentication *AuthenticationService
 	CurrentUser    *CurrentUserService
 	Repository     *RepositoryService
+	Query          *QueryService
 }
 
 // NewClient returns a new Tenable API client.
@@ -52,6 +63,7 @@ type Client struct {
 // As an alternative you can use Session Cookie based authentication provided by this package as well.
 // See https://docs.tenable.com/tenablesc/api_best_practices/Content/ScApiBestPractices/APIKeyAuthorization.htm
 // baseURL is the HTTP endpoint of your Tenable instance and should always be specified with a trailing slash.
+// Use New to configure the client with functional options instead.
 func NewClient(httpClient httpClient, baseURL string) (*Client, error) {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
@@ -75,6 +87,7 @@ func NewClient(httpClient httpClient, baseURL string) (*Client, error) {
 	c.Authentication = &AuthenticationService{client: c}
 	c.CurrentUser = &CurrentUserService{client: c}
 	c.Repository = &RepositoryService{client: c}
+	c.Query = &QueryService{client: c}
 	return c, nil
 }
 
@@ -96,12 +109,10 @@ func (c *Client) NewRawRequestWithContext(ctx context.Context, method, urlStr st
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if c.Authentication.authType == authTypeAPIKey {
-		// Set basic auth information
-		if c.Authentication.client.Authentication.apiKey != "" {
-			req.Header.Set("X-Apikey", fmt.Sprintf("accesskey=%s; secretkey=%s;", c.Authentication.apiKey, c.Authentication.apiSecret))
-		}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
+	c.Authentication.setAuthHeaders(req)
 
 	return req, nil
 }
@@ -128,14 +139,16 @@ func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr strin
 
 	u := c.baseURL.ResolveReference(rel)
 
-	var buf io.ReadWriter
+	// The encoded body is handed over as a *bytes.Reader so that the request sets GetBody
+	// and can be replayed on retries or after a re-login.
+	var buf io.Reader
 	if body != nil {
-		buf = new(bytes.Buffer)
-		err = json.NewEncoder(buf).Encode(body)
-		//		fmt.Println(buf)
+		encoded := new(bytes.Buffer)
+		err = json.NewEncoder(encoded).Encode(body)
 		if err != nil {
 			return nil, err
 		}
+		buf = bytes.NewReader(encoded.Bytes())
 	}
 
 	req, err := newRequestWithContext(ctx, method, u.String(), buf)
@@ -143,12 +156,10 @@ func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr strin
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if c.Authentication.authType == authTypeAPIKey {
-		// Set basic auth information
-		if c.Authentication.apiKey != "" {
-			req.Header.Set("X-Apikey", fmt.Sprintf("accesskey=%s; secretkey=%s;", c.Authentication.apiKey, c.Authentication.apiSecret))
-		}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
+	c.Authentication.setAuthHeaders(req)
 
 	return req, nil
 }
@@ -182,15 +193,24 @@ func addOptions(s string, opt interface{}) (string, error) {
 
 // CheckResponse checks the API response for errors, and returns them if present.
 // A response is considered an error if it has a status code outside the 200 range.
-// The caller is responsible to analyze the response body.
-// The body can contain JSON (if the error is intended) or xml (sometimes Tenable just failes).
+// The returned error is an *APIError carrying the error_code and error_msg of the response envelope, if any.
+// The body is left readable for callers that want to analyze it further
+// (it can contain JSON if the error is intended, or xml as sometimes Tenable just fails).
 func CheckResponse(r *http.Response) error {
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return nil
 	}
 
-	err := fmt.Errorf("request failed. Please analyze the request body for more details. Status code: %d", r.StatusCode)
-	return err
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if apiErr := newAPIError(r, body); apiErr != nil {
+		return apiErr
+	}
+	return &APIError{StatusCode: r.StatusCode}
 }
 
 // Response represents Tenable API response. It wraps http.Response returned from
@@ -204,12 +224,51 @@ type Response struct {
 }
 
 // Do sends an API request and returns the API response.
+// Transient failures are retried according to the RetryPolicy set with SetRetryPolicy.
 // The API response is JSON decoded and stored in the value pointed to by v, or returned as an error if an API error has occurred.
 func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
-	httpResp, err := c.client.Do(req)
+	return c.do(req, v, false)
+}
+
+// doStream is Do for callers that read the response body themselves: the attempt is reported
+// once they close the body, so the hooks see the duration and size of the whole body.
+func (c *Client) doStream(req *http.Request) (*Response, error) {
+	return c.do(req, nil, true)
+}
+
+func (c *Client) do(req *http.Request, v interface{}, stream bool) (resp *Response, err error) {
+	if c.Authentication.needsLogin(req) {
+		// Session credentials were configured but no session was acquired yet
+		req, err = c.Authentication.reauthenticate(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	httpResp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
+	// report the attempt to the logger and hooks even if the caller never closes the body
+	defer func() {
+		if !stream || err != nil {
+			finishBody(httpResp)
+		}
+	}()
+
+	if c.Authentication.shouldReauthenticate(req, httpResp) {
+		// The session expired: log in again and replay the request once with the new session
+		finishBody(httpResp)
+		req2, authErr := c.Authentication.reauthenticate(req)
+		if authErr != nil {
+			return newResponse(httpResp, nil), fmt.Errorf("tenable: %w, renewing the session failed: %w", CheckResponse(httpResp), authErr)
+		}
+		httpResp.Body.Close()
+		httpResp, err = c.send(req2)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	err = CheckResponse(httpResp)
 	if err != nil {
@@ -219,28 +278,47 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 	}
 
 	if v != nil {
-		// Open a NewDecoder and defer closing the reader only if there is a provided interface to decode to
+		// Read the body only if there is a provided interface to decode to
 		defer httpResp.Body.Close()
-		// body, err := ioutil.ReadAll(httpResp.Body)
-		// fmt.Println(string(body))
-		// err = json.Unmarshal(body, v)
-		//fmt.Println(err.Error())
-		err = json.NewDecoder(httpResp.Body).Decode(v)
+		body, err := io.ReadAll(httpResp.Body)
+		if err != nil {
+			return newResponse(httpResp, nil), err
+		}
+		// Tenable reports most failures with HTTP 200 and a non-zero error_code in the envelope
+		if apiErr := newAPIError(httpResp, body); apiErr != nil {
+			return newResponse(httpResp, nil), apiErr
+		}
+		err = json.Unmarshal(body, v)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	resp := newResponse(httpResp, v)
-	return resp, err
+	return newResponse(httpResp, v), nil
 }
 
 func newResponse(r *http.Response, v interface{}) *Response {
 	resp := &Response{Response: r}
-	//resp.populatePageValues(v)
+	resp.populatePageValues(v)
 	return resp
 }
 
+// pager is implemented by responses carrying paging information.
+type pager interface {
+	pageValues() (startAt, maxResults, total int, ok bool)
+}
+
+// populatePageValues sets the paging fields of r from v, if v carries paging information.
+func (r *Response) populatePageValues(v interface{}) {
+	if p, ok := v.(pager); ok {
+		if startAt, maxResults, total, ok := p.pageValues(); ok {
+			r.StartAt = startAt
+			r.MaxResults = maxResults
+			r.Total = total
+		}
+	}
+}
+
 // APIKeyAuthTransport is an http.RoundTripper that authenticates all requests
 // using HTTP APIKey Authentication with the provided username and password.
 type APIKeyAuthTransport struct {
@@ -277,21 +355,6 @@ func (t *APIKeyAuthTransport) transport() http.RoundTripper {
 	return http.DefaultTransport
 }
 
-func interface2Int(v interface{}) (int, error) {
-	switch v := v.(type) {
-	case float64:
-		return int(v), nil
-	case string:
-		c, err := strconv.Atoi(v)
-		if err != nil {
-			return 0, err
-		}
-		return c, nil
-	default:
-		return 0, fmt.Errorf("conversion to int from %T not supported", v)
-	}
-}
-
 // cloneRequest returns a clone of the provided *http.Request.
 // The clone is a shallow copy of the struct and its Header map.
 func cloneRequest(r *http.Request) *http.Request {
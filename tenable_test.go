@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package tenable
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -148,3 +150,21 @@ func TestCheckResponse(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckResponse_Error(t *testing.T) {
+	r := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(strings.NewReader(`{"error_code":146,"error_msg":"Invalid parameters."}`)),
+	}
+	err := CheckResponse(r)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %v", err)
+	}
+	if apiErr.Code != 146 || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+	if body, _ := io.ReadAll(r.Body); len(body) == 0 {
+		t.Error("Expected the body to remain readable")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenabletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	tenable "github.com/IBM/go-tenable"
+)
+
+// resultSet is the response of /rest/analysis. Unlike tenable.AnalysisResultSet it
+// always contains all paging fields, the way Tenable.sc sends them.
+type resultSet struct {
+	TotalRecords             tenable.Int        `json:"totalRecords"`
+	ReturnedRecords          int                `json:"returnedRecords"`
+	StartOffset              tenable.Int        `json:"startOffset"`
+	EndOffset                tenable.Int        `json:"endOffset"`
+	MatchingDataElementCount tenable.Int        `json:"matchingDataElementCount"`
+	Results                  []tenable.Analysis `json:"results"`
+}
+
+func (s *Server) handleAnalysis(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, ErrorCodeInvalidParameters, "Invalid method.")
+		return
+	}
+	var body tenable.AnalysisBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, ErrorCodeInvalidParameters, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if body.Type != "vuln" {
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, fmt.Sprintf("Invalid analysis type '%s'.", body.Type))
+		return
+	}
+	switch body.SourceType {
+	case "", "cumulative", "patched":
+	default:
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, fmt.Sprintf("Invalid source type '%s'.", body.SourceType))
+		return
+	}
+	tool := body.Query.Tool
+	if tool == "" {
+		tool = body.Query.VulnTool
+	}
+	if tool != "listvuln" && tool != "vulndetails" {
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, fmt.Sprintf("Invalid tool '%s'.", tool))
+		return
+	}
+
+	s.mu.Lock()
+	vulnerabilities := append([]tenable.Analysis(nil), s.vulnerabilities...)
+	s.mu.Unlock()
+
+	matching := make([]tenable.Analysis, 0, len(vulnerabilities))
+	for _, v := range vulnerabilities {
+		ok, err := matchFilters(v, body.Query.Filters)
+		if err != nil {
+			writeError(w, http.StatusOK, ErrorCodeInvalidParameters, err.Error())
+			return
+		}
+		if ok {
+			matching = append(matching, v)
+		}
+	}
+
+	if err := sortResults(matching, body.SortField, body.SortDir); err != nil {
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, err.Error())
+		return
+	}
+
+	start, end := body.Query.StartOffset, body.Query.EndOffset
+	if start < 0 || end < start {
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, "Invalid offsets.")
+		return
+	}
+	page := []tenable.Analysis{}
+	if start < int64(len(matching)) {
+		if end > int64(len(matching)) {
+			end = int64(len(matching))
+		}
+		page = matching[start:end]
+	}
+	writeResponse(w, resultSet{
+		TotalRecords:             tenable.Int(len(matching)),
+		ReturnedRecords:          len(page),
+		StartOffset:              tenable.Int(body.Query.StartOffset),
+		EndOffset:                tenable.Int(body.Query.EndOffset),
+		MatchingDataElementCount: -1,
+		Results:                  page,
+	})
+}
+
+// sortResults sorts the vulnerabilities by field, descending if dir is DESC.
+func sortResults(vulnerabilities []tenable.Analysis, field string, dir tenable.SortDirection) error {
+	var less func(a, b tenable.Analysis) bool
+	switch field {
+	case "":
+		return nil
+	case "pluginID":
+		less = func(a, b tenable.Analysis) bool { return a.PluginID < b.PluginID }
+	case "severity":
+		less = func(a, b tenable.Analysis) bool { return a.Severity.ID < b.Severity.ID }
+	case "port":
+		less = func(a, b tenable.Analysis) bool { return a.Port < b.Port }
+	case "ip":
+		less = func(a, b tenable.Analysis) bool {
+			return bytes.Compare(net.ParseIP(a.IP).To16(), net.ParseIP(b.IP).To16()) < 0
+		}
+	case "vprScore":
+		less = func(a, b tenable.Analysis) bool { return a.VPRScore < b.VPRScore }
+	default:
+		return fmt.Errorf("Invalid sort field '%s'.", field)
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		if strings.EqualFold(string(dir), "DESC") {
+			return less(vulnerabilities[j], vulnerabilities[i])
+		}
+		return less(vulnerabilities[i], vulnerabilities[j])
+	})
+	return nil
+}
+
+// matchFilters reports whether v matches all filters.
+func matchFilters(v tenable.Analysis, filters []tenable.AnalysisFilter) (bool, error) {
+	for _, f := range filters {
+		name := f.FilterName
+		if name == "" {
+			name = f.ID
+		}
+		var ok bool
+		var err error
+		switch name {
+		case "pluginID":
+			ok, err = matchNumbers(int64(v.PluginID), f)
+		case "severity":
+			ok, err = matchNumbers(int64(v.Severity.ID), f)
+		case "ip":
+			ok, err = matchIP(v.IP, f)
+		case "repository":
+			ok, err = matchRepository(v.Repository.ID, f)
+		default:
+			return false, fmt.Errorf("Invalid filter '%s'.", name)
+		}
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchNumbers matches n against a comma separated list of numbers and ranges like "1,3-5".
+func matchNumbers(n int64, f tenable.AnalysisFilter) (bool, error) {
+	value := fmt.Sprint(f.Value)
+	switch f.Operator {
+	case "=", "!=":
+		found := false
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			low, high, isRange := strings.Cut(part, "-")
+			from, err := strconv.ParseInt(low, 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+			}
+			to := from
+			if isRange {
+				if to, err = strconv.ParseInt(high, 10, 64); err != nil {
+					return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+				}
+			}
+			if from <= n && n <= to {
+				found = true
+			}
+		}
+		return found == (f.Operator == "="), nil
+	case ">=", "<=":
+		bound, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+		}
+		if f.Operator == ">=" {
+			return n >= bound, nil
+		}
+		return n <= bound, nil
+	}
+	return false, fmt.Errorf("Invalid operator '%s' for filter '%s'.", f.Operator, f.FilterName)
+}
+
+// matchIP matches ip against a comma separated list of addresses, CIDRs and ranges like "10.0.0.1-10.0.0.9".
+func matchIP(ip string, f tenable.AnalysisFilter) (bool, error) {
+	if f.Operator != "=" && f.Operator != "!=" {
+		return false, fmt.Errorf("Invalid operator '%s' for filter '%s'.", f.Operator, f.FilterName)
+	}
+	addr := net.ParseIP(ip)
+	value := fmt.Sprint(f.Value)
+	found := false
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.Contains(part, "/"):
+			_, network, err := net.ParseCIDR(part)
+			if err != nil {
+				return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+			}
+			found = found || (addr != nil && network.Contains(addr))
+		case strings.Contains(part, "-"):
+			low, high, _ := strings.Cut(part, "-")
+			from, to := net.ParseIP(strings.TrimSpace(low)), net.ParseIP(strings.TrimSpace(high))
+			if from == nil || to == nil {
+				return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+			}
+			found = found || (addr != nil && bytes.Compare(addr.To16(), from.To16()) >= 0 && bytes.Compare(addr.To16(), to.To16()) <= 0)
+		default:
+			other := net.ParseIP(part)
+			if other == nil {
+				return false, fmt.Errorf("Invalid value '%s' for filter '%s'.", value, f.FilterName)
+			}
+			found = found || (addr != nil && addr.Equal(other))
+		}
+	}
+	return found == (f.Operator == "="), nil
+}
+
+// matchRepository matches id against a list of repository objects like [{"id":"1"}].
+func matchRepository(id tenable.ID, f tenable.AnalysisFilter) (bool, error) {
+	if f.Operator != "=" && f.Operator != "!=" {
+		return false, fmt.Errorf("Invalid operator '%s' for filter '%s'.", f.Operator, f.FilterName)
+	}
+	raw, err := json.Marshal(f.Value)
+	if err != nil {
+		return false, err
+	}
+	var repositories []tenable.Repository
+	if err := json.Unmarshal(raw, &repositories); err != nil {
+		return false, fmt.Errorf("Invalid value for filter '%s'.", f.FilterName)
+	}
+	found := false
+	for _, repository := range repositories {
+		found = found || repository.ID == id
+	}
+	return found == (f.Operator == "="), nil
+}
+
+// project returns the JSON members of v listed in the comma separated fields. The id is always included.
+func project(v interface{}, fields string) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	projected := map[string]interface{}{"id": all["id"]}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected, nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tenabletest provides an in-memory fake Tenable.sc server for testing code built on go-tenable.
+//
+//	srv := tenabletest.NewServer()
+//	defer srv.Close()
+//	srv.AddRepositories(tenable.Repository{ID: 1, Name: "repo1"})
+//	srv.AddVulnerabilities(tenable.Analysis{PluginID: 19506, IP: "10.0.0.1", Repository: tenable.Repository{ID: 1}})
+//	c := srv.Client()
+//
+// The server answers /rest/currentUser, /rest/repository, /rest/analysis and /rest/token with the
+// standard response envelope and honors the paging and the common filters of analysis queries.
+package tenabletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	tenable "github.com/IBM/go-tenable"
+)
+
+// Error codes returned in the envelope of failed requests.
+const (
+	// ErrorCodeInvalidToken is returned with HTTP 401 for missing or wrong credentials.
+	ErrorCodeInvalidToken = 74
+	// ErrorCodeNotFound is returned if the requested object does not exist.
+	ErrorCodeNotFound = 143
+	// ErrorCodeInvalidParameters is returned for malformed requests, unknown tools or filters.
+	ErrorCodeInvalidParameters = 146
+)
+
+// sessionToken is the token handed out by the fake /rest/token endpoint.
+const sessionToken = 1337
+
+// Server is a stateful fake Tenable.sc instance built on httptest.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	users           []tenable.User
+	passwords       map[string]string
+	currentUser     tenable.ID
+	repositories    []tenable.Repository
+	vulnerabilities []tenable.Analysis
+	accessKey       string
+	secretKey       string
+	sessions        map[string]bool
+}
+
+// NewServer starts a fake Tenable.sc server. The caller must call Close when finished.
+// Without seeded users, /rest/currentUser answers with a default admin user.
+func NewServer() *Server {
+	s := &Server{
+		passwords: map[string]string{},
+		sessions:  map[string]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/currentUser", s.authenticated(s.handleCurrentUser))
+	mux.HandleFunc("/rest/repository", s.authenticated(s.handleRepository))
+	mux.HandleFunc("/rest/analysis", s.authenticated(s.handleAnalysis))
+	mux.HandleFunc("/rest/token", s.handleToken)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, ErrorCodeNotFound, fmt.Sprintf("Invalid resource %s", r.URL.Path))
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a tenable.Client talking to the server, authenticated with the
+// API key configured by RequireAPIKey, if any.
+func (s *Server) Client() *tenable.Client {
+	s.mu.Lock()
+	accessKey, secretKey := s.accessKey, s.secretKey
+	s.mu.Unlock()
+
+	opts := []tenable.Option{tenable.WithHTTPClient(s.Server.Client())}
+	if accessKey != "" {
+		opts = append(opts, tenable.WithAPIKey(accessKey, secretKey))
+	}
+	c, err := tenable.New(s.URL, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("tenabletest: creating client: %v", err))
+	}
+	return c
+}
+
+// RequireAPIKey makes the server reject requests which are neither authenticated with the
+// given API key nor with a session created through /rest/token.
+func (s *Server) RequireAPIKey(accessKey, secretKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessKey = accessKey
+	s.secretKey = secretKey
+}
+
+// AddUsers seeds users. The first user becomes the current user unless SetCurrentUser is called.
+func (s *Server) AddUsers(users ...tenable.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.users) == 0 && len(users) > 0 {
+		s.currentUser = users[0].ID
+	}
+	s.users = append(s.users, users...)
+}
+
+// SetPassword allows username to log in with password through /rest/token.
+func (s *Server) SetPassword(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwords[username] = password
+}
+
+// SetCurrentUser selects the seeded user returned by /rest/currentUser.
+func (s *Server) SetCurrentUser(id tenable.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currentUser = id
+}
+
+// AddRepositories seeds repositories.
+func (s *Server) AddRepositories(repositories ...tenable.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repositories = append(s.repositories, repositories...)
+}
+
+// AddVulnerabilities seeds vulnerability records returned by analysis queries.
+// Records are returned in the order they were added.
+func (s *Server) AddVulnerabilities(vulnerabilities ...tenable.Analysis) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vulnerabilities = append(s.vulnerabilities, vulnerabilities...)
+}
+
+// authenticated rejects requests without valid credentials if an API key is required.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		accessKey, secretKey := s.accessKey, s.secretKey
+		session := s.sessions[r.Header.Get("X-SecurityCenter")]
+		s.mu.Unlock()
+
+		if accessKey != "" && !session &&
+			r.Header.Get("X-Apikey") != fmt.Sprintf("accesskey=%s; secretkey=%s;", accessKey, secretKey) {
+			writeError(w, http.StatusUnauthorized, ErrorCodeInvalidToken, "Invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		var login struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+			writeError(w, http.StatusBadRequest, ErrorCodeInvalidParameters, "Invalid parameters.")
+			return
+		}
+		s.mu.Lock()
+		password, ok := s.passwords[login.Username]
+		s.mu.Unlock()
+		if !ok || password != login.Password {
+			writeError(w, http.StatusForbidden, 1, "Invalid login credentials.")
+			return
+		}
+		token := fmt.Sprint(sessionToken)
+		s.mu.Lock()
+		s.sessions[token] = true
+		s.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "TNS_SESSIONID", Value: fmt.Sprintf("fake-session-%s", token)})
+		writeResponse(w, map[string]interface{}{"releaseSession": false, "token": sessionToken, "unassociatedCert": "false"})
+	case "DELETE":
+		s.mu.Lock()
+		delete(s.sessions, r.Header.Get("X-SecurityCenter"))
+		s.mu.Unlock()
+		writeResponse(w, "")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrorCodeInvalidParameters, "Invalid method.")
+	}
+}
+
+func (s *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, ErrorCodeInvalidParameters, "Invalid method.")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.users) == 0 {
+		writeResponse(w, tenable.User{ID: 1, Username: "admin", Firstname: "Admin", Lastname: "User", AuthType: "tns"})
+		return
+	}
+	for _, user := range s.users {
+		if user.ID == s.currentUser {
+			writeResponse(w, user)
+			return
+		}
+	}
+	writeError(w, http.StatusOK, ErrorCodeNotFound, fmt.Sprintf("User #%s not found", s.currentUser))
+}
+
+func (s *Server) handleRepository(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, ErrorCodeInvalidParameters, "Invalid method.")
+		return
+	}
+	switch r.URL.Query().Get("type") {
+	case "", "All", "Local", "Remote", "Offline":
+	default:
+		writeError(w, http.StatusOK, ErrorCodeInvalidParameters, "Invalid type.")
+		return
+	}
+
+	s.mu.Lock()
+	repositories := append([]tenable.Repository(nil), s.repositories...)
+	s.mu.Unlock()
+
+	fields := r.URL.Query().Get("fields")
+	if fields == "" {
+		writeResponse(w, repositories)
+		return
+	}
+	projected := make([]map[string]interface{}, 0, len(repositories))
+	for _, repository := range repositories {
+		p, err := project(repository, fields)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrorCodeInvalidParameters, err.Error())
+			return
+		}
+		projected = append(projected, p)
+	}
+	writeResponse(w, projected)
+}
+
+// writeResponse writes v wrapped into a successful envelope.
+func writeResponse(w http.ResponseWriter, v interface{}) {
+	writeEnvelope(w, http.StatusOK, tenable.Envelope[interface{}]{Type: "regular", Response: v, Warnings: []string{}, Timestamp: int(time.Now().Unix())})
+}
+
+// writeError writes an envelope with the given error_code and message.
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	writeEnvelope(w, status, tenable.Envelope[interface{}]{Type: "regular", Response: "", ErrorCode: code, ErrorMsg: msg, Warnings: []string{}, Timestamp: int(time.Now().Unix())})
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, envelope tenable.Envelope[interface{}]) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(envelope)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenabletest
+
+import (
+	"errors"
+	"testing"
+
+	tenable "github.com/IBM/go-tenable"
+)
+
+func seededServer() *Server {
+	srv := NewServer()
+	srv.AddUsers(tenable.User{ID: 7, Username: "analyst"}, tenable.User{ID: 8, Username: "auditor"})
+	srv.AddRepositories(
+		tenable.Repository{ID: 1, Name: "repo1", DataFormat: "IPv4"},
+		tenable.Repository{ID: 2, Name: "repo2", DataFormat: "IPv4"},
+	)
+	repo1, repo2 := tenable.Repository{ID: 1, Name: "repo1"}, tenable.Repository{ID: 2, Name: "repo2"}
+	srv.AddVulnerabilities(
+		tenable.Analysis{PluginID: 19506, Severity: tenable.Severity{ID: 0, Name: "Info"}, IP: "10.0.0.1", Repository: repo1},
+		tenable.Analysis{PluginID: 119500, Severity: tenable.Severity{ID: 4, Name: "Critical"}, IP: "10.0.0.1", Repository: repo1},
+		tenable.Analysis{PluginID: 119500, Severity: tenable.Severity{ID: 4, Name: "Critical"}, IP: "10.0.1.5", Repository: repo2},
+		tenable.Analysis{PluginID: 57582, Severity: tenable.Severity{ID: 2, Name: "Medium"}, IP: "192.168.1.1", Repository: repo2},
+	)
+	return srv
+}
+
+func vulnQuery(start, end int64, filters ...tenable.AnalysisFilter) tenable.AnalysisBody {
+	return tenable.AnalysisBody{
+		Type:       "vuln",
+		SourceType: "cumulative",
+		Query: tenable.AnalysisQuery{
+			Type: "vuln", Tool: "listvuln", SourceType: "cumulative",
+			StartOffset: start, EndOffset: end, Filters: filters,
+		},
+	}
+}
+
+func filter(name, operator string, value interface{}) tenable.AnalysisFilter {
+	return tenable.AnalysisFilter{ID: name, FilterName: name, Operator: operator, Type: "vuln", IsPredefined: true, Value: value}
+}
+
+func TestServer_CurrentUserAndRepositories(t *testing.T) {
+	srv := seededServer()
+	defer srv.Close()
+	srv.RequireAPIKey("foo", "bar")
+	c := srv.Client()
+
+	user, _, err := c.CurrentUser.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "analyst" {
+		t.Errorf("Username: %s, want analyst", user.Username)
+	}
+	srv.SetCurrentUser(8)
+	if user, _, _ = c.CurrentUser.Get(); user.Username != "auditor" {
+		t.Errorf("Username: %s, want auditor", user.Username)
+	}
+
+	repos, _, err := c.Repository.Get("All", "id,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[1].Name != "repo2" || repos[1].DataFormat != "" {
+		t.Errorf("Unexpected repositories %+v", repos)
+	}
+
+	unauthenticated, _ := tenable.NewClient(nil, srv.URL)
+	if _, _, err := unauthenticated.CurrentUser.Get(); !errors.Is(err, tenable.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestServer_Session(t *testing.T) {
+	srv := seededServer()
+	defer srv.Close()
+	srv.RequireAPIKey("foo", "bar")
+	srv.SetPassword("analyst", "secret")
+
+	c, err := tenable.New(srv.URL, tenable.WithSessionCredentials("analyst", "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CurrentUser.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Authentication.Logout(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_AnalysisPagingAndFilters(t *testing.T) {
+	srv := seededServer()
+	defer srv.Close()
+	c := srv.Client()
+
+	page, _, err := c.Analysis.Post(vulnQuery(1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Response.TotalRecords != 4 || page.Response.ReturnedRecords != 2 || len(page.Response.Results) != 2 {
+		t.Fatalf("Unexpected page %+v", page.Response)
+	}
+	if page.Response.Results[0].PluginID != 119500 || page.Response.Results[0].IP != "10.0.0.1" {
+		t.Errorf("Unexpected first result %+v", page.Response.Results[0])
+	}
+
+	tests := []struct {
+		name    string
+		filters []tenable.AnalysisFilter
+		want    int
+	}{
+		{"pluginID", []tenable.AnalysisFilter{filter("pluginID", "=", "119500,57582")}, 3},
+		{"pluginID range", []tenable.AnalysisFilter{filter("pluginID", "=", "19000-20000")}, 1},
+		{"severity", []tenable.AnalysisFilter{filter("severity", "=", "3,4")}, 2},
+		{"severity not", []tenable.AnalysisFilter{filter("severity", "!=", "0")}, 3},
+		{"ip", []tenable.AnalysisFilter{filter("ip", "=", "10.0.0.0/16")}, 3},
+		{"ip range", []tenable.AnalysisFilter{filter("ip", "=", "192.168.1.1-192.168.1.9")}, 1},
+		{"repository", []tenable.AnalysisFilter{filter("repository", "=", []map[string]string{{"id": "2"}})}, 2},
+		{"combined", []tenable.AnalysisFilter{filter("severity", "=", "4"), filter("repository", "=", []map[string]string{{"id": "1"}})}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := c.Analysis.Post(vulnQuery(0, 50, tt.filters...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int(got.Response.TotalRecords) != tt.want || len(got.Response.Results) != tt.want {
+				t.Errorf("Got %d results (total %d), want %d", len(got.Response.Results), got.Response.TotalRecords, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_AnalysisErrors(t *testing.T) {
+	srv := seededServer()
+	defer srv.Close()
+	c := srv.Client()
+
+	invalid := []tenable.AnalysisBody{
+		vulnQuery(0, 50, filter("pluginText", "=", "foo")),
+		vulnQuery(0, 50, filter("severity", "~=", "4")),
+		vulnQuery(0, 50, filter("ip", "=", "not-an-ip")),
+		vulnQuery(10, 5),
+		{Type: "event", Query: tenable.AnalysisQuery{Tool: "listvuln"}},
+		{Type: "vuln", Query: tenable.AnalysisQuery{Tool: "sumfoo"}},
+	}
+	for _, body := range invalid {
+		_, _, err := c.Analysis.Post(body)
+		if !errors.Is(err, &tenable.APIError{Code: ErrorCodeInvalidParameters}) {
+			t.Errorf("Expected error_code %d for %+v, got %v", ErrorCodeInvalidParameters, body, err)
+		}
+	}
+}
+
+func TestServer_AnalysisSort(t *testing.T) {
+	srv := seededServer()
+	defer srv.Close()
+	c := srv.Client()
+
+	body := vulnQuery(0, 50)
+	body.SortField, body.SortDir = "severity", tenable.SortDesc
+	got, _, err := c.Analysis.Post(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var severities []tenable.ID
+	for _, v := range got.Response.Results {
+		severities = append(severities, v.Severity.ID)
+	}
+	if len(severities) != 4 || severities[0] != 4 || severities[1] != 4 || severities[2] != 2 || severities[3] != 0 {
+		t.Errorf("Unexpected order of severities %v", severities)
+	}
+}
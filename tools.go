@@ -0,0 +1,464 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tool is the analysis tool of a vulnerability query, it determines the shape of the results.
+type Tool string
+
+// Analysis tools of vulnerability queries.
+const (
+	ToolListVuln        Tool = "listvuln"
+	ToolVulnDetails     Tool = "vulndetails"
+	ToolVulnIPDetail    Tool = "vulnipdetail"
+	ToolVulnIPSummary   Tool = "vulnipsummary"
+	ToolSumID           Tool = "sumid"
+	ToolSumIP           Tool = "sumip"
+	ToolSumPort         Tool = "sumport"
+	ToolSumProtocol     Tool = "sumprotocol"
+	ToolSumClassA       Tool = "sumclassa"
+	ToolSumClassB       Tool = "sumclassb"
+	ToolSumClassC       Tool = "sumclassc"
+	ToolSumSeverity     Tool = "sumseverity"
+	ToolSumFamily       Tool = "sumfamily"
+	ToolSumCVE          Tool = "sumcve"
+	ToolSumIAVM         Tool = "sumiavm"
+	ToolSumRemediation  Tool = "sumremediation"
+	ToolListOS          Tool = "listos"
+	ToolListSoftware    Tool = "listsoftware"
+	ToolListServices    Tool = "listservices"
+	ToolListMailClients Tool = "listmailclients"
+	ToolListWebServers  Tool = "listwebservers"
+	ToolSumDateID       Tool = "sumdateid"
+)
+
+// vulnTools maps the vulnerability analysis tools to their result types.
+var vulnTools = map[Tool]ToolResult{
+	ToolListVuln:        Analysis{},
+	ToolVulnDetails:     VulnDetail{},
+	ToolVulnIPDetail:    VulnIPDetail{},
+	ToolVulnIPSummary:   VulnIPSummary{},
+	ToolSumID:           PluginSummary{},
+	ToolSumIP:           IPSummary{},
+	ToolSumPort:         PortSummary{},
+	ToolSumProtocol:     ProtocolSummary{},
+	ToolSumClassA:       ClassASummary{},
+	ToolSumClassB:       ClassBSummary{},
+	ToolSumClassC:       ClassCSummary{},
+	ToolSumSeverity:     SeveritySummary{},
+	ToolSumFamily:       FamilySummary{},
+	ToolSumCVE:          CVESummary{},
+	ToolSumIAVM:         IAVMSummary{},
+	ToolSumRemediation:  RemediationSummary{},
+	ToolListOS:          OSResult{},
+	ToolListSoftware:    SoftwareResult{},
+	ToolListServices:    ServiceResult{},
+	ToolListMailClients: MailClientResult{},
+	ToolListWebServers:  WebServerResult{},
+	ToolSumDateID:       DateSummary{},
+}
+
+// Valid reports whether t is a known vulnerability analysis tool.
+func (t Tool) Valid() bool {
+	_, ok := vulnTools[t]
+	return ok
+}
+
+// ToolResult is implemented by the result types of the analysis tools.
+// Tool is called on the zero value, so it must not depend on the receiver.
+type ToolResult interface {
+	Tool() Tool
+}
+
+// SeverityCounts are the per severity totals of the summary tools.
+type SeverityCounts struct {
+	Total            Int    `json:"total,omitempty"`
+	Score            Int    `json:"score,omitempty"`
+	SeverityInfo     Int    `json:"severityInfo,omitempty"`
+	SeverityLow      Int    `json:"severityLow,omitempty"`
+	SeverityMedium   Int    `json:"severityMedium,omitempty"`
+	SeverityHigh     Int    `json:"severityHigh,omitempty"`
+	SeverityCritical Int    `json:"severityCritical,omitempty"`
+	SeverityAll      string `json:"severityAll,omitempty"`
+}
+
+// Tool returns ToolListVuln.
+func (Analysis) Tool() Tool { return ToolListVuln }
+
+// VulnDetail is a result of the vulndetails tool, a finding with the plugin details.
+type VulnDetail struct {
+	Analysis
+	PluginName  string `json:"pluginName,omitempty"`
+	Synopsis    string `json:"synopsis,omitempty"`
+	Description string `json:"description,omitempty"`
+	Solution    string `json:"solution,omitempty"`
+	// SeeAlso are reference URLs
+	SeeAlso      Lines  `json:"seeAlso,omitempty"`
+	RiskFactor   string `json:"riskFactor,omitempty"`
+	StigSeverity string `json:"stigSeverity,omitempty"`
+	CheckType    string `json:"checkType,omitempty"`
+	Version      string `json:"version,omitempty"`
+
+	// CVSS scores are 0 for plugins without CVSS metrics
+	CVSSV2BaseScore     Float  `json:"baseScore,omitempty"`
+	CVSSV2TemporalScore Float  `json:"temporalScore,omitempty"`
+	CVSSV2Vector        string `json:"cvssVector,omitempty"`
+	CVSSV3BaseScore     Float  `json:"cvssV3BaseScore,omitempty"`
+	CVSSV3TemporalScore Float  `json:"cvssV3TemporalScore,omitempty"`
+	CVSSV3Vector        string `json:"cvssV3Vector,omitempty"`
+	CVSSV4BaseScore     Float  `json:"cvssV4BaseScore,omitempty"`
+	CVSSV4Vector        string `json:"cvssV4Vector,omitempty"`
+
+	CVE  List `json:"cve,omitempty"`
+	BID  List `json:"bid,omitempty"`
+	CPE  List `json:"cpe,omitempty"`
+	Xref List `json:"xref,omitempty"`
+
+	// Dates are the zero time if Tenable does not know them
+	PluginPubDate time.Time `json:"-"`
+	PluginModDate time.Time `json:"-"`
+	PatchPubDate  time.Time `json:"-"`
+	VulnPubDate   time.Time `json:"-"`
+	FirstSeen     time.Time `json:"-"`
+	LastSeen      time.Time `json:"-"`
+
+	ExploitAvailable  Bool   `json:"exploitAvailable,omitempty"`
+	ExploitEase       string `json:"exploitEase,omitempty"`
+	ExploitFrameworks string `json:"exploitFrameworks,omitempty"`
+
+	HasBeenMitigated      Bool   `json:"hasBeenMitigated,omitempty"`
+	AcceptRisk            Bool   `json:"acceptRisk,omitempty"`
+	AcceptRiskRuleComment string `json:"acceptRiskRuleComment,omitempty"`
+	RecastRisk            Bool   `json:"recastRisk,omitempty"`
+	RecastRiskRuleComment string `json:"recastRiskRuleComment,omitempty"`
+	OperatingSystem       string `json:"operatingSystem,omitempty"`
+	PluginText            string `json:"pluginText,omitempty"`
+}
+
+// vulnDetailDates are the dates of a VulnDetail as Tenable encodes them.
+type vulnDetailDates struct {
+	PluginPubDate Epoch `json:"pluginPubDate,omitempty"`
+	PluginModDate Epoch `json:"pluginModDate,omitempty"`
+	PatchPubDate  Epoch `json:"patchPubDate,omitempty"`
+	VulnPubDate   Epoch `json:"vulnPubDate,omitempty"`
+	FirstSeen     Epoch `json:"firstSeen,omitempty"`
+	LastSeen      Epoch `json:"lastSeen,omitempty"`
+}
+
+// vulnDetail has the fields of VulnDetail without its JSON methods.
+type vulnDetail VulnDetail
+
+// MarshalJSON encodes d with its dates as Epochs.
+func (d VulnDetail) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		vulnDetail
+		vulnDetailDates
+	}{vulnDetail(d), vulnDetailDates{
+		PluginPubDate: NewEpoch(d.PluginPubDate),
+		PluginModDate: NewEpoch(d.PluginModDate),
+		PatchPubDate:  NewEpoch(d.PatchPubDate),
+		VulnPubDate:   NewEpoch(d.VulnPubDate),
+		FirstSeen:     NewEpoch(d.FirstSeen),
+		LastSeen:      NewEpoch(d.LastSeen),
+	}})
+}
+
+// UnmarshalJSON decodes a vulndetails result, its dates from Epochs.
+func (d *VulnDetail) UnmarshalJSON(b []byte) error {
+	var v struct {
+		*vulnDetail
+		vulnDetailDates
+	}
+	v.vulnDetail = (*vulnDetail)(d)
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	d.PluginPubDate = v.vulnDetailDates.PluginPubDate.Time()
+	d.PluginModDate = v.vulnDetailDates.PluginModDate.Time()
+	d.PatchPubDate = v.vulnDetailDates.PatchPubDate.Time()
+	d.VulnPubDate = v.vulnDetailDates.VulnPubDate.Time()
+	d.FirstSeen = v.vulnDetailDates.FirstSeen.Time()
+	d.LastSeen = v.vulnDetailDates.LastSeen.Time()
+	return nil
+}
+
+// Xrefs returns the cross references of d of a kind, e.g. "IAVA" or "CWE", without the kind prefix.
+func (d VulnDetail) Xrefs(kind string) []string {
+	var refs []string
+	for _, x := range d.Xref {
+		k, ref, ok := strings.Cut(x, "#")
+		if ok && strings.EqualFold(strings.TrimSpace(k), kind) {
+			refs = append(refs, strings.TrimSpace(ref))
+		}
+	}
+	return refs
+}
+
+// Tool returns ToolVulnDetails.
+func (VulnDetail) Tool() Tool { return ToolVulnDetails }
+
+// VulnIPHost is a list of hosts of a repository affected by a plugin.
+type VulnIPHost struct {
+	// IPList is a comma separated list of addresses and ranges
+	IPList     string     `json:"iplist,omitempty"`
+	Repository Repository `json:"repository,omitempty"`
+}
+
+// VulnIPSummary is a result of the vulnipsummary tool, a plugin with its affected hosts.
+type VulnIPSummary struct {
+	PluginID ID           `json:"pluginID"`
+	Name     string       `json:"name,omitempty"`
+	Severity Severity     `json:"severity,omitempty"`
+	Family   Family       `json:"family,omitempty"`
+	Total    Int          `json:"total,omitempty"`
+	Hosts    []VulnIPHost `json:"hosts,omitempty"`
+}
+
+// Tool returns ToolVulnIPSummary.
+func (VulnIPSummary) Tool() Tool { return ToolVulnIPSummary }
+
+// VulnIPDetail is a result of the vulnipdetail tool, which has the shape of the vulnipsummary results.
+type VulnIPDetail struct {
+	VulnIPSummary
+}
+
+// Tool returns ToolVulnIPDetail.
+func (VulnIPDetail) Tool() Tool { return ToolVulnIPDetail }
+
+// PluginSummary is a result of the sumid tool, a plugin with the number of findings.
+type PluginSummary struct {
+	PluginID  ID       `json:"pluginID"`
+	Name      string   `json:"name,omitempty"`
+	Family    Family   `json:"family,omitempty"`
+	Severity  Severity `json:"severity,omitempty"`
+	Total     Int      `json:"total,omitempty"`
+	HostTotal Int      `json:"hostTotal,omitempty"`
+	VPRScore  Float    `json:"vprScore,omitempty"`
+}
+
+// Tool returns ToolSumID.
+func (PluginSummary) Tool() Tool { return ToolSumID }
+
+// IPSummary is a result of the sumip tool, a host with its severity counts.
+type IPSummary struct {
+	IP          string     `json:"ip,omitempty"`
+	UUID        string     `json:"uuid,omitempty"`
+	DNSName     string     `json:"dnsName,omitempty"`
+	NetBiosName string     `json:"netbiosName,omitempty"`
+	MACAddress  string     `json:"macAddress,omitempty"`
+	OS          string     `json:"os,omitempty"`
+	OSCPE       string     `json:"osCPE,omitempty"`
+	PolicyName  string     `json:"policyName,omitempty"`
+	PluginSet   string     `json:"pluginSet,omitempty"`
+	LastScan    Epoch      `json:"lastScan,omitempty"`
+	LastAuthRun string     `json:"lastAuthRun,omitempty"`
+	HasPassive  string     `json:"hasPassive,omitempty"`
+	Repository  Repository `json:"repository,omitempty"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumIP.
+func (IPSummary) Tool() Tool { return ToolSumIP }
+
+// PortSummary is a result of the sumport tool.
+type PortSummary struct {
+	Port Int `json:"port"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumPort.
+func (PortSummary) Tool() Tool { return ToolSumPort }
+
+// ProtocolSummary is a result of the sumprotocol tool.
+type ProtocolSummary struct {
+	Protocol Int `json:"protocol"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumProtocol.
+func (ProtocolSummary) Tool() Tool { return ToolSumProtocol }
+
+// ClassASummary is a result of the sumclassa tool, IP is the network, e.g. "10.0.0.0".
+type ClassASummary struct {
+	IP string `json:"ip"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumClassA.
+func (ClassASummary) Tool() Tool { return ToolSumClassA }
+
+// ClassBSummary is a result of the sumclassb tool, IP is the network, e.g. "10.1.0.0".
+type ClassBSummary struct {
+	IP string `json:"ip"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumClassB.
+func (ClassBSummary) Tool() Tool { return ToolSumClassB }
+
+// ClassCSummary is a result of the sumclassc tool, IP is the network, e.g. "10.1.2.0".
+type ClassCSummary struct {
+	IP string `json:"ip"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumClassC.
+func (ClassCSummary) Tool() Tool { return ToolSumClassC }
+
+// SeveritySummary is a result of the sumseverity tool.
+type SeveritySummary struct {
+	Severity Severity `json:"severity"`
+	Count    Int      `json:"count"`
+}
+
+// Tool returns ToolSumSeverity.
+func (SeveritySummary) Tool() Tool { return ToolSumSeverity }
+
+// FamilySummary is a result of the sumfamily tool.
+type FamilySummary struct {
+	Family Family `json:"family"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumFamily.
+func (FamilySummary) Tool() Tool { return ToolSumFamily }
+
+// CVESummary is a result of the sumcve tool.
+type CVESummary struct {
+	CVEID     string   `json:"cveID"`
+	Severity  Severity `json:"severity,omitempty"`
+	Total     Int      `json:"total,omitempty"`
+	HostTotal Int      `json:"hostTotal,omitempty"`
+}
+
+// Tool returns ToolSumCVE.
+func (CVESummary) Tool() Tool { return ToolSumCVE }
+
+// IAVMSummary is a result of the sumiavm tool.
+type IAVMSummary struct {
+	IAVMID    string   `json:"iavmID"`
+	Severity  Severity `json:"severity,omitempty"`
+	Total     Int      `json:"total,omitempty"`
+	HostTotal Int      `json:"hostTotal,omitempty"`
+}
+
+// Tool returns ToolSumIAVM.
+func (IAVMSummary) Tool() Tool { return ToolSumIAVM }
+
+// RemediationSummary is a result of the sumremediation tool, a solution and the findings it fixes.
+type RemediationSummary struct {
+	PluginID        ID     `json:"pluginID"`
+	Solution        string `json:"solution,omitempty"`
+	CPE             string `json:"cpe,omitempty"`
+	Total           Int    `json:"total,omitempty"`
+	HostTotal       Int    `json:"hostTotal,omitempty"`
+	Score           Int    `json:"score,omitempty"`
+	ScorePctg       string `json:"scorePctg,omitempty"`
+	MSBulletinTotal Int    `json:"msbulletinTotal,omitempty"`
+	CVETotal        Int    `json:"cveTotal,omitempty"`
+	VPRScore        Float  `json:"vprScore,omitempty"`
+	// RemediationList is a comma separated list of the plugin IDs fixed by the solution
+	RemediationList string `json:"remediationList,omitempty"`
+}
+
+// Tool returns ToolSumRemediation.
+func (RemediationSummary) Tool() Tool { return ToolSumRemediation }
+
+// OSResult is a result of the listos tool, an operating system and the number of hosts running it.
+type OSResult struct {
+	Name  string `json:"name"`
+	Total Int    `json:"total"`
+}
+
+// Tool returns ToolListOS.
+func (OSResult) Tool() Tool { return ToolListOS }
+
+// SoftwareResult is a result of the listsoftware tool, Name usually is a CPE.
+type SoftwareResult struct {
+	Name  string `json:"name"`
+	Total Int    `json:"total"`
+}
+
+// Tool returns ToolListSoftware.
+func (SoftwareResult) Tool() Tool { return ToolListSoftware }
+
+// ServiceResult is a result of the listservices tool.
+type ServiceResult struct {
+	Name  string `json:"name"`
+	Total Int    `json:"total"`
+}
+
+// Tool returns ToolListServices.
+func (ServiceResult) Tool() Tool { return ToolListServices }
+
+// MailClientResult is a result of the listmailclients tool.
+type MailClientResult struct {
+	Name  string `json:"name"`
+	Total Int    `json:"total"`
+}
+
+// Tool returns ToolListMailClients.
+func (MailClientResult) Tool() Tool { return ToolListMailClients }
+
+// WebServerResult is a result of the listwebservers tool.
+type WebServerResult struct {
+	Name  string `json:"name"`
+	Total Int    `json:"total"`
+}
+
+// Tool returns ToolListWebServers.
+func (WebServerResult) Tool() Tool { return ToolListWebServers }
+
+// withTool sets the tool of T on the query in body and fails if it already names another tool.
+func withTool[T ToolResult](body AnalysisBody) (AnalysisBody, error) {
+	var zero T
+	tool := zero.Tool()
+	if body.Query.Tool != "" && body.Query.Tool != string(tool) {
+		return body, fmt.Errorf("tenable: query tool %q does not match the %T results of %q", body.Query.Tool, zero, tool)
+	}
+	body.Query.Tool = string(tool)
+	return body, nil
+}
+
+// Query posts an analysis query for the tool of T and decodes the results as T, e.g.
+//
+//	summary, _, err := tenable.Query[tenable.SeveritySummary](ctx, client.Analysis, body)
+//
+// The tool of body.Query is set from T, a different tool is an error.
+// Sorting and columns are checked against the fields of T, see AnalysisBody.Validate.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func Query[T ToolResult](ctx context.Context, s *AnalysisService, body AnalysisBody) (*Envelope[ResultSet[T]], *Response, error) {
+	body, err := withTool[T](body)
+	if err == nil {
+		body, err = body.normalize()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", body)
+}
+
+// StreamQuery is the streaming form of Query: fn is called for every result as soon as it is decoded.
+// See AnalysisService.StreamWithContext.
+func StreamQuery[T ToolResult](ctx context.Context, s *AnalysisService, body AnalysisBody, fn func(T) error) (*Envelope[ResultSet[T]], *Response, error) {
+	body, err := withTool[T](body)
+	if err == nil {
+		body, err = body.normalize()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return stream(ctx, s.client, "/rest/analysis", body, fn)
+}
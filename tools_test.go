@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQuery_SumSeverity(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body AnalysisBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Query.Tool != "sumseverity" {
+			t.Errorf("Tool: %q, want sumseverity", body.Query.Tool)
+		}
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"2","returnedRecords":2,"startOffset":"0","endOffset":"2","results":[
+			{"severity":{"id":"4","name":"Critical","description":"Critical Severity"},"count":"12"},
+			{"severity":{"id":"3","name":"High","description":"High Severity"},"count":"40"}]},"error_code":0,"error_msg":"","warnings":[],"timestamp":1553525692}`))
+	})
+
+	summary, resp, err := Query[SeveritySummary](context.Background(), testClient.Analysis, AnalysisBody{Type: "vuln"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := summary.Response.Results
+	if len(results) != 2 || results[0].Severity.ID != 4 || results[0].Count != 12 || results[1].Count != 40 {
+		t.Errorf("Unexpected results %+v", results)
+	}
+	if resp.Total != 2 {
+		t.Errorf("Total: %d, want 2", resp.Total)
+	}
+}
+
+func TestQuery_SumIPStream(t *testing.T) {
+	setup()
+	defer teardown()
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"regular","response":{"totalRecords":"1","results":[
+			{"ip":"10.0.0.1","dnsName":"host.example.com","repository":{"id":"1","name":"repo1"},"lastScan":"1553525692",
+			 "total":"7","score":"52","severityInfo":"2","severityLow":"1","severityMedium":"1","severityHigh":"2","severityCritical":"1"}]},"error_code":0}`))
+	})
+
+	var hosts []IPSummary
+	_, _, err := StreamQuery(context.Background(), testClient.Analysis, AnalysisBody{}, func(h IPSummary) error {
+		hosts = append(hosts, h)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("Got %d hosts, want 1", len(hosts))
+	}
+	h := hosts[0]
+	if h.IP != "10.0.0.1" || h.Repository.ID != 1 || h.Total != 7 || h.SeverityCritical != 1 || h.LastScan.Time().Unix() != 1553525692 {
+		t.Errorf("Unexpected host %+v", h)
+	}
+}
+
+func TestQuery_ToolMismatch(t *testing.T) {
+	body := AnalysisBody{Query: AnalysisQuery{Tool: "listvuln"}}
+	if _, _, err := Query[PortSummary](context.Background(), &AnalysisService{}, body); err == nil {
+		t.Error("Expected an error for a mismatching tool")
+	}
+}
+
+func TestToolResults(t *testing.T) {
+	results := []ToolResult{
+		Analysis{}, VulnDetail{}, VulnIPDetail{}, VulnIPSummary{}, PluginSummary{}, IPSummary{}, PortSummary{},
+		ProtocolSummary{}, ClassASummary{}, ClassBSummary{}, ClassCSummary{}, SeveritySummary{}, FamilySummary{},
+		CVESummary{}, IAVMSummary{}, RemediationSummary{}, OSResult{}, SoftwareResult{}, ServiceResult{},
+		MailClientResult{}, WebServerResult{}, DateSummary{},
+	}
+	seen := map[Tool]bool{}
+	for _, r := range results {
+		if !r.Tool().Valid() || seen[r.Tool()] {
+			t.Errorf("%T: invalid or duplicate tool %q", r, r.Tool())
+		}
+		if reflect.TypeOf(vulnTools[r.Tool()]) != reflect.TypeOf(r) {
+			t.Errorf("%T: tool %q maps to %T", r, r.Tool(), vulnTools[r.Tool()])
+		}
+		seen[r.Tool()] = true
+	}
+	if len(seen) != len(vulnTools) {
+		t.Errorf("Got result types for %d tools, want %d", len(seen), len(vulnTools))
+	}
+	if detail, summary := ToolFields(ToolVulnIPDetail), ToolFields(ToolVulnIPSummary); !reflect.DeepEqual(detail, summary) {
+		t.Errorf("vulnipdetail fields %q, want the vulnipsummary fields %q", detail, summary)
+	}
+}
+
+func TestVulnDetail_UnmarshalJSON(t *testing.T) {
+	data := `{"pluginID":"104743","severity":{"id":"3","name":"High"},"ip":"10.0.0.1","port":"443","protocol":"TCP",
+		"pluginName":"TLS Version 1.0 Protocol Detection","synopsis":"The remote service encrypts traffic using an older version of TLS.",
+		"seeAlso":"https://tools.ietf.org/html/draft-ietf-tls-oldversions-deprecate-00\nhttp://www.nessus.org/u?c8ae820d",
+		"riskFactor":"Medium","stigSeverity":"","checkType":"remote","version":"1.27",
+		"baseScore":"6.1","temporalScore":"","cvssVector":"AV:N/AC:H/Au:N/C:C/I:P/A:N",
+		"cvssV3BaseScore":"6.5","cvssV3TemporalScore":"","cvssV3Vector":"AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:L/A:N",
+		"cve":"CVE-2011-3389, CVE-2015-0204","bid":"","cpe":"","xref":"CWE #327, IAVA #2019-A-0001",
+		"pluginPubDate":"1511352000","pluginModDate":"1648900800","patchPubDate":"-1","vulnPubDate":"1316476800",
+		"firstSeen":"1553000000","lastSeen":"1554000000","exploitAvailable":"No","exploitEase":"","exploitFrameworks":"",
+		"hasBeenMitigated":"0","acceptRisk":"0","recastRisk":"0","pluginText":"<plugin_output>TLSv1 is enabled</plugin_output>"}`
+	var d VulnDetail
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.PluginID != 104743 || d.Port != 443 || d.SeverityLevel() != SeverityHigh || d.PluginName == "" {
+		t.Errorf("Unexpected finding %+v", d.Analysis)
+	}
+	if d.CVSSV2BaseScore != 6.1 || d.CVSSV2TemporalScore != 0 || d.CVSSV3BaseScore != 6.5 || d.CVSSV3Vector == "" {
+		t.Errorf("Unexpected CVSS %v %v %v %q", d.CVSSV2BaseScore, d.CVSSV2TemporalScore, d.CVSSV3BaseScore, d.CVSSV3Vector)
+	}
+	if !reflect.DeepEqual([]string(d.CVE), []string{"CVE-2011-3389", "CVE-2015-0204"}) || len(d.BID) != 0 || len(d.SeeAlso) != 2 {
+		t.Errorf("Unexpected lists %q %q %q", d.CVE, d.BID, d.SeeAlso)
+	}
+	if iava := d.Xrefs("iava"); len(iava) != 1 || iava[0] != "2019-A-0001" {
+		t.Errorf("Unexpected IAVA references %q", iava)
+	}
+	if want := time.Date(2017, 11, 22, 12, 0, 0, 0, time.UTC); !d.PluginPubDate.Equal(want) {
+		t.Errorf("PluginPubDate %s, want %s", d.PluginPubDate, want)
+	}
+	if !d.PatchPubDate.IsZero() || d.LastSeen.Unix() != 1554000000 {
+		t.Errorf("Unexpected dates %s %s", d.PatchPubDate, d.LastSeen)
+	}
+	if d.ExploitAvailable || d.AcceptRisk {
+		t.Errorf("Unexpected booleans %+v", d)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again VulnDetail
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, d) {
+		t.Errorf("Round trip changed the detail\n%+v\nwant %+v", again, d)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DateSummary is a result of the sumdateid tool, the severity counts of a day of trending data.
+type DateSummary struct {
+	Date Epoch `json:"date"`
+	SeverityCounts
+}
+
+// Tool returns ToolSumDateID.
+func (DateSummary) Tool() Tool { return ToolSumDateID }
+
+// TrendPoint is the number of vulnerabilities per severity at a point in time.
+type TrendPoint struct {
+	Time     time.Time
+	Info     int64
+	Low      int64
+	Medium   int64
+	High     int64
+	Critical int64
+}
+
+// Total returns the number of vulnerabilities of all severities.
+func (p TrendPoint) Total() int64 {
+	return p.Info + p.Low + p.Medium + p.High + p.Critical
+}
+
+// TrendOptions selects the data of a trend analysis.
+type TrendOptions struct {
+	// Start and End limit the time series, both are required
+	Start time.Time
+	End   time.Time
+	// Filters restrict the counted vulnerabilities, e.g. to some repositories with trending enabled
+	Filters []AnalysisFilter
+	// SourceType is SourceCumulative if empty
+	SourceType SourceType
+	// Workers is the number of groups TrendGroupsWithContext queries in parallel, DefaultFetchWorkers if not positive
+	Workers int
+}
+
+// trendBody is the request of the sumdateid tool.
+type trendBody struct {
+	AnalysisBody
+	StartTime Epoch `json:"startTime"`
+	EndTime   Epoch `json:"endTime"`
+}
+
+// TrendWithContext returns the vulnerability counts per severity over the date range of opts as time series,
+// ordered by time. It uses the sumdateid tool, which serves the trending data of repositories with trending enabled.
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func (s *AnalysisService) TrendWithContext(ctx context.Context, opts TrendOptions) ([]TrendPoint, *Response, error) {
+	if opts.Start.IsZero() || opts.End.IsZero() || opts.End.Before(opts.Start) {
+		return nil, nil, errors.New("tenable: a trend needs a start before its end")
+	}
+	body, err := AnalysisBody{
+		SourceType: string(opts.SourceType),
+		Query: AnalysisQuery{
+			Tool:      string(ToolSumDateID),
+			Filters:   opts.Filters,
+			EndOffset: int64(opts.End.Sub(opts.Start)/(24*time.Hour)) + 1,
+		},
+	}.normalize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := trendBody{AnalysisBody: body, StartTime: NewEpoch(opts.Start), EndTime: NewEpoch(opts.End)}
+	page, resp, err := post[ResultSet[DateSummary]](ctx, s.client, "/rest/analysis", req)
+	if err != nil {
+		return nil, resp, err
+	}
+	points := make([]TrendPoint, 0, len(page.Response.Results))
+	for _, r := range page.Response.Results {
+		t := r.Date.Time()
+		if t.Before(opts.Start) || t.After(opts.End) {
+			continue
+		}
+		points = append(points, TrendPoint{
+			Time:     t,
+			Info:     int64(r.SeverityInfo),
+			Low:      int64(r.SeverityLow),
+			Medium:   int64(r.SeverityMedium),
+			High:     int64(r.SeverityHigh),
+			Critical: int64(r.SeverityCritical),
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, resp, nil
+}
+
+// Trend wraps TrendWithContext using the background context.
+func (s *AnalysisService) Trend(opts TrendOptions) ([]TrendPoint, *Response, error) {
+	return s.TrendWithContext(context.Background(), opts)
+}
+
+// TrendGroupsWithContext returns a time series per group, e.g. per repository or asset.
+// The filters of each group are added to the filters of opts. The groups are queried in parallel
+// by a bounded pool of opts.Workers workers, the first error cancels the remaining queries.
+// If ctx is done before all groups are queried, its error is returned.
+func (s *AnalysisService) TrendGroupsWithContext(ctx context.Context, opts TrendOptions, groups map[string][]AnalysisFilter) (map[string][]TrendPoint, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	series := make(map[string][]TrendPoint, len(groups))
+	jobs := make(chan string)
+	workers := (&FetchOptions{Workers: opts.Workers}).workers()
+	for w := 0; w < workers && w < len(groups); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				groupOpts := opts
+				groupOpts.Filters = append(append([]AnalysisFilter(nil), opts.Filters...), groups[name]...)
+				points, _, err := s.TrendWithContext(ctx, groupOpts)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					series[name] = points
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for name := range groups {
+		jobs <- name
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
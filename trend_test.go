@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalysisService_Trend(t *testing.T) {
+	setup()
+	defer teardown()
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query := body["query"].(map[string]interface{})
+		if query["tool"] != "sumdateid" || body["sourceType"] != "cumulative" || body["startTime"] != fmt.Sprint(start.Unix()) || body["endTime"] != fmt.Sprint(end.Unix()) {
+			t.Errorf("Unexpected body %v", body)
+		}
+		// unordered and with a day outside of the range
+		fmt.Fprintf(w, `{"type":"regular","response":{"totalRecords":"4","results":[
+			{"date":"%d","severityInfo":"10","severityLow":"5","severityMedium":"3","severityHigh":"2","severityCritical":"1"},
+			{"date":"%d","severityInfo":"9","severityLow":"5","severityMedium":"3","severityHigh":"2","severityCritical":"0"},
+			{"date":"%d","severityInfo":"8","severityLow":"4","severityMedium":"3","severityHigh":"1","severityCritical":"0"},
+			{"date":"%d","severityCritical":"9"}]},"error_code":0}`,
+			start.AddDate(0, 0, 1).Unix(), start.Unix(), end.Unix(), end.AddDate(0, 0, 1).Unix())
+	})
+
+	points, _, err := testClient.Analysis.Trend(TrendOptions{Start: start, End: end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("Got %d points, want 3", len(points))
+	}
+	if !points[0].Time.Equal(start) || !points[2].Time.Equal(end) {
+		t.Errorf("Unexpected times %v %v", points[0].Time, points[2].Time)
+	}
+	if p := points[1]; p.Info != 10 || p.Critical != 1 || p.Total() != 21 {
+		t.Errorf("Unexpected point %+v", p)
+	}
+
+	if _, _, err := testClient.Analysis.Trend(TrendOptions{Start: end, End: start}); err == nil {
+		t.Error("Expected an error for an empty date range")
+	}
+}
+
+func TestAnalysisService_TrendGroups(t *testing.T) {
+	setup()
+	defer teardown()
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		var body AnalysisBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body.Query.Filters) != 2 {
+			t.Errorf("Expected the common and the group filter, got %+v", body.Query.Filters)
+		}
+		repo := body.Query.Filters[1].Value.([]interface{})[0].(map[string]interface{})["id"]
+		fmt.Fprintf(w, `{"type":"regular","response":{"results":[{"date":"%d","severityHigh":"%s"}]},"error_code":0}`, start.Unix(), repo)
+	})
+
+	severity, _ := SeverityAtLeastFilter(SeverityHigh)
+	repo1, _ := RepositoryFilter(OpEqual, 1)
+	repo2, _ := RepositoryFilter(OpEqual, 2)
+	opts := TrendOptions{Start: start, End: start.AddDate(0, 0, 30), Filters: []AnalysisFilter{severity}}
+	series, err := testClient.Analysis.TrendGroupsWithContext(context.Background(), opts, map[string][]AnalysisFilter{
+		"repo1": {repo1},
+		"repo2": {repo2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 || series["repo1"][0].High != 1 || series["repo2"][0].High != 2 {
+		t.Errorf("Unexpected series %+v", series)
+	}
+}
+
+func TestAnalysisService_TrendGroups_Workers(t *testing.T) {
+	setup()
+	defer teardown()
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	var running, most int32
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintf(w, `{"type":"regular","response":{"results":[{"date":"%d","severityHigh":"1"}]},"error_code":0}`, start.Unix())
+	})
+
+	groups := make(map[string][]AnalysisFilter)
+	for i := 1; i <= 6; i++ {
+		repo, _ := RepositoryFilter(OpEqual, ID(i))
+		groups[fmt.Sprintf("repo%d", i)] = []AnalysisFilter{repo}
+	}
+	opts := TrendOptions{Start: start, End: start.AddDate(0, 0, 30), Workers: 2}
+	series, err := testClient.Analysis.TrendGroupsWithContext(context.Background(), opts, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 6 {
+		t.Errorf("Expected 6 series, got %d", len(series))
+	}
+	if most > 2 {
+		t.Errorf("Expected at most 2 parallel queries, got %d", most)
+	}
+}
+
+func TestAnalysisService_TrendGroups_Cancelled(t *testing.T) {
+	setup()
+	defer teardown()
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	testMux.HandleFunc("/rest/analysis", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"type":"regular","response":{"results":[{"date":"%d","severityHigh":"1"}]},"error_code":0}`, start.Unix())
+	})
+
+	repo1, _ := RepositoryFilter(OpEqual, 1)
+	repo2, _ := RepositoryFilter(OpEqual, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts := TrendOptions{Start: start, End: start.AddDate(0, 0, 30)}
+	series, err := testClient.Analysis.TrendGroupsWithContext(ctx, opts, map[string][]AnalysisFilter{
+		"repo1": {repo1},
+		"repo2": {repo2},
+	})
+	if err == nil {
+		t.Errorf("Expected an error for a cancelled context, got %+v", series)
+	}
+}
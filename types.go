@@ -0,0 +1,282 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ID identifies a Tenable object (repository, user, plugin, ...).
+// Tenable sends IDs as quoted strings ("516") and occasionally as numbers (-1);
+// both decode into ID. IDs are encoded as quoted strings the way Tenable sends them.
+type ID int64
+
+// String returns the decimal representation of id.
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalJSON encodes id as a quoted string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(id.String())), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string into id.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	n, err := unmarshalInt(b)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid ID %s: %w", b, err)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// Int is a number which Tenable sends as a quoted string, e.g. "totalRecords": "1".
+// It decodes from both JSON numbers and numeric strings and is encoded as a quoted string.
+type Int int64
+
+// String returns the decimal representation of i.
+func (i Int) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// MarshalJSON encodes i as a quoted string.
+func (i Int) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(i.String())), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string into i.
+func (i *Int) UnmarshalJSON(b []byte) error {
+	n, err := unmarshalInt(b)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid number %s: %w", b, err)
+	}
+	*i = Int(n)
+	return nil
+}
+
+// Float is a decimal number which Tenable sends as a quoted string, e.g. "vprScore": "6.7".
+// It decodes from both JSON numbers and numeric strings and is encoded as a quoted string.
+type Float float64
+
+// String returns the shortest decimal representation of f.
+func (f Float) String() string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 64)
+}
+
+// MarshalJSON encodes f as a quoted string.
+func (f Float) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(f.String())), nil
+}
+
+// UnmarshalJSON decodes a JSON number, numeric string or empty string into f.
+func (f *Float) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid number %s: %w", b, err)
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid number %s: %w", b, err)
+	}
+	*f = Float(v)
+	return nil
+}
+
+// Bool is a boolean which Tenable sends as a quoted string, e.g. "locked": "false".
+// It decodes from JSON booleans and the strings "true"/"false"/"1"/"0"/"Yes"/"No" and is encoded as a quoted string.
+type Bool bool
+
+// MarshalJSON encodes b as "true" or "false".
+func (b Bool) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatBool(bool(b)))), nil
+}
+
+// UnmarshalJSON decodes a JSON boolean or boolean string into b.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	s, err := unquote(data)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid boolean %s: %w", data, err)
+	}
+	if s == "" {
+		*b = false
+		return nil
+	}
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "yes") || strings.EqualFold(s, "no") {
+		*b = Bool(strings.EqualFold(s, "yes"))
+		return nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid boolean %s: %w", data, err)
+	}
+	*b = Bool(v)
+	return nil
+}
+
+// List is a list of values which Tenable sends as a single string separated by commas or new lines,
+// e.g. "cve": "CVE-2014-3566,CVE-2014-8730". It decodes from that form and from JSON arrays
+// and is encoded as a comma separated string.
+type List []string
+
+// String returns the values of l separated by commas.
+func (l List) String() string {
+	return strings.Join(l, ",")
+}
+
+// MarshalJSON encodes l as a comma separated string.
+func (l List) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON decodes a separated string, an array of strings or null into l.
+func (l *List) UnmarshalJSON(b []byte) error {
+	values, err := unmarshalList(b, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	*l = values
+	return err
+}
+
+// Lines is a list of values which Tenable sends as a single string separated by new lines,
+// e.g. the reference URLs of "seeAlso", which may contain commas themselves.
+// It decodes from that form and from JSON arrays and is encoded as a new line separated string.
+type Lines []string
+
+// String returns the values of l separated by new lines.
+func (l Lines) String() string {
+	return strings.Join(l, "\n")
+}
+
+// MarshalJSON encodes l as a new line separated string.
+func (l Lines) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON decodes a new line separated string, an array of strings or null into l.
+func (l *Lines) UnmarshalJSON(b []byte) error {
+	values, err := unmarshalList(b, func(r rune) bool { return r == '\n' || r == '\r' })
+	*l = values
+	return err
+}
+
+// unmarshalList decodes a string of values separated by the runes sep reports, an array of strings or null.
+func unmarshalList(b []byte, sep func(rune) bool) ([]string, error) {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		var values []string
+		if err := json.Unmarshal(b, &values); err != nil {
+			return nil, fmt.Errorf("tenable: invalid list %s: %w", b, err)
+		}
+		return values, nil
+	}
+	s, err := unquote(b)
+	if err != nil {
+		return nil, fmt.Errorf("tenable: invalid list %s: %w", b, err)
+	}
+	var values []string
+	for _, v := range strings.FieldsFunc(s, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// Epoch is a point in time which Tenable sends as seconds since the Unix epoch,
+// usually as a quoted string, e.g. "lastLogin": "1454350174".
+// The zero value means the time is not set, as does -1, which Tenable sends for unknown dates.
+type Epoch int64
+
+// NewEpoch returns the Epoch of t.
+func NewEpoch(t time.Time) Epoch {
+	if t.IsZero() {
+		return 0
+	}
+	return Epoch(t.Unix())
+}
+
+// Time returns e as time.Time, or the zero time if e is not set.
+func (e Epoch) Time() time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(e), 0)
+}
+
+// IsZero reports whether e is not set.
+func (e Epoch) IsZero() bool {
+	return e <= 0
+}
+
+// String returns e formatted as RFC 3339, or an empty string if e is not set.
+func (e Epoch) String() string {
+	if e <= 0 {
+		return ""
+	}
+	return e.Time().UTC().Format(time.RFC3339)
+}
+
+// MarshalJSON encodes e as a quoted number of seconds.
+func (e Epoch) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(int64(e), 10))), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string of seconds into e.
+func (e *Epoch) UnmarshalJSON(b []byte) error {
+	n, err := unmarshalInt(b)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid timestamp %s: %w", b, err)
+	}
+	*e = Epoch(n)
+	return nil
+}
+
+// unmarshalInt decodes a JSON number, numeric string, empty string or null into an int64.
+func unmarshalInt(b []byte) (int64, error) {
+	s, err := unquote(b)
+	if err != nil {
+		return 0, err
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return n, nil
+	}
+	// some endpoints send integral values as floats, e.g. 6.0
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil || f != math.Trunc(f) {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
+// unquote returns the content of a JSON string, the literal of any other JSON value,
+// or an empty string for null.
+func unquote(b []byte) (string, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return "", nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		err := json.Unmarshal(b, &s)
+		return s, err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypes_Unmarshal(t *testing.T) {
+	var v struct {
+		ID      ID    `json:"id"`
+		Org     ID    `json:"org"`
+		Total   Int   `json:"total"`
+		Empty   Int   `json:"empty"`
+		Float   Int   `json:"float"`
+		VPR     Float `json:"vpr"`
+		CVSS    Float `json:"cvss"`
+		Locked  Bool  `json:"locked"`
+		Enabled Bool  `json:"enabled"`
+		Login   Epoch `json:"login"`
+		Never   Epoch `json:"never"`
+	}
+	data := `{"id":"516","org":-1,"total":"1200","empty":"","float":6.0,"vpr":"6.7","cvss":9.8,"locked":"false","enabled":true,"login":"1454350174","never":null}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != 516 || v.Org != -1 || v.Total != 1200 || v.Empty != 0 || v.Float != 6 || v.VPR != 6.7 || v.CVSS != 9.8 {
+		t.Errorf("Unexpected numbers %+v", v)
+	}
+	if v.Locked || !v.Enabled {
+		t.Errorf("Unexpected booleans %+v", v)
+	}
+	if want := time.Date(2016, 2, 1, 18, 9, 34, 0, time.UTC); !v.Login.Time().Equal(want) {
+		t.Errorf("Login: %s, want %s", v.Login.Time(), want)
+	}
+	if !v.Never.IsZero() || !v.Never.Time().IsZero() {
+		t.Errorf("Expected an unset epoch, got %s", v.Never)
+	}
+
+	for _, invalid := range []string{`{"id":"abc"}`, `{"id":1.5}`, `{"locked":"maybe"}`, `{"login":{}}`, `{"vpr":"high"}`} {
+		if err := json.Unmarshal([]byte(invalid), &v); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestTypes_Marshal(t *testing.T) {
+	v := struct {
+		ID     ID    `json:"id"`
+		Total  Int   `json:"total"`
+		Locked Bool  `json:"locked"`
+		Login  Epoch `json:"login"`
+	}{ID: 516, Total: 3, Locked: true, Login: NewEpoch(time.Unix(1454350174, 0))}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"516","total":"3","locked":"true","login":"1454350174"}`; string(b) != want {
+		t.Errorf("Marshal: %s, want %s", b, want)
+	}
+}
+
+func TestTypes_Lists(t *testing.T) {
+	var v struct {
+		CVE     List  `json:"cve"`
+		BID     List  `json:"bid"`
+		SeeAlso Lines `json:"seeAlso"`
+	}
+	data := `{"cve":"CVE-2014-3566, CVE-2014-8730\nCVE-2015-0204","bid":null,"seeAlso":"https://example.com/a,b\r\nhttps://example.com/c\n"}`
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual([]string(v.CVE), []string{"CVE-2014-3566", "CVE-2014-8730", "CVE-2015-0204"}) || len(v.BID) != 0 {
+		t.Errorf("Unexpected lists %q %q", v.CVE, v.BID)
+	}
+	if !reflect.DeepEqual([]string(v.SeeAlso), []string{"https://example.com/a,b", "https://example.com/c"}) {
+		t.Errorf("Unexpected lines %q", v.SeeAlso)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"cve":"CVE-2014-3566,CVE-2014-8730,CVE-2015-0204","bid":"","seeAlso":"https://example.com/a,b\nhttps://example.com/c"}`; string(b) != want {
+		t.Errorf("Marshal: %s, want %s", b, want)
+	}
+}
@@ -6,31 +6,35 @@ SPDX-License-Identifier: Apache-2.0
 
 package tenable
 
+import (
+	"context"
+)
+
 type User struct {
-	ID                 interface{} `json:"id"`
-	Status             string      `json:"status,omitempty"`             // "0",
-	Username           string      `json:"username,omitempty"`           // "admin",
-	LDAPUsername       string      `json:"ldapUsername,omitempty"`       // "",
-	Firstname          string      `json:"firstname,omitempty"`          // "Admin",
-	Lastname           string      `json:"lastname,omitempty"`           // "User",
-	Title              string      `json:"title,omitempty"`              // "Application Administrator",
-	Email              string      `json:"email,omitempty"`              // "",
-	Address            string      `json:"address,omitempty"`            // "",
-	City               string      `json:"city,omitempty"`               // "",
-	State              string      `json:"state,omitempty"`              // "",
-	Country            string      `json:"country,omitempty"`            // "",
-	Phone              string      `json:"phone,omitempty"`              // "",
-	Fax                string      `json:"fax,omitempty"`                // "",
-	CreatedTime        string      `json:"createdTime,omitempty"`        // "1432921843",
-	ModifiedTime       string      `json:"modifiedTime,omitempty"`       // "1453473716",
-	LastLogin          string      `json:"lastLogin,omitempty"`          // "1454350174",
-	LastLoginIP        string      `json:"lastLoginIP,omitempty"`        // "172.20.0.0",
-	MustChangePassword string      `json:"mustChangePassword,omitempty"` // "false",
-	Locked             string      `json:"locked,omitempty"`             // "false",
-	FailedLogin        string      `json:"failedLogins,omitempty"`       // "0",
-	AuthType           string      `json:"authType,omitempty"`           // "tns",
-	Fingerprint        string      `json:"fingerprint,omitempty"`        // null,
-	Password           string      `json:"password,omitempty"`           // "SET",
+	ID                 ID     `json:"id"`
+	Status             Int    `json:"status,omitempty"`             // "0",
+	Username           string `json:"username,omitempty"`           // "admin",
+	LDAPUsername       string `json:"ldapUsername,omitempty"`       // "",
+	Firstname          string `json:"firstname,omitempty"`          // "Admin",
+	Lastname           string `json:"lastname,omitempty"`           // "User",
+	Title              string `json:"title,omitempty"`              // "Application Administrator",
+	Email              string `json:"email,omitempty"`              // "",
+	Address            string `json:"address,omitempty"`            // "",
+	City               string `json:"city,omitempty"`               // "",
+	State              string `json:"state,omitempty"`              // "",
+	Country            string `json:"country,omitempty"`            // "",
+	Phone              string `json:"phone,omitempty"`              // "",
+	Fax                string `json:"fax,omitempty"`                // "",
+	CreatedTime        Epoch  `json:"createdTime,omitempty"`        // "1432921843",
+	ModifiedTime       Epoch  `json:"modifiedTime,omitempty"`       // "1453473716",
+	LastLogin          Epoch  `json:"lastLogin,omitempty"`          // "1454350174",
+	LastLoginIP        string `json:"lastLoginIP,omitempty"`        // "172.20.0.0",
+	MustChangePassword Bool   `json:"mustChangePassword,omitempty"` // "false",
+	Locked             Bool   `json:"locked,omitempty"`             // "false",
+	FailedLogin        Int    `json:"failedLogins,omitempty"`       // "0",
+	AuthType           string `json:"authType,omitempty"`           // "tns",
+	Fingerprint        string `json:"fingerprint,omitempty"`        // null,
+	Password           string `json:"password,omitempty"`           // "SET",
 	Preferences        []PreferenceItem
 	Organization       Organization
 	OrgName            string `json:"orgName,omitempty"`
@@ -45,7 +49,7 @@ type PreferenceItem struct {
 }
 
 type Organization struct {
-	ID          interface{}
+	ID          ID
 	Name        string
 	Description string
 }
@@ -57,7 +61,46 @@ type UserPrefItem struct {
 }
 
 type Role struct {
-	ID          interface{}
+	ID          ID
 	Name        string
 	Description string
 }
+
+// UserTool is the analysis tool of a user query.
+type UserTool string
+
+// Analysis tools of user queries.
+const (
+	UserToolUser UserTool = "user"
+)
+
+// UserResult is implemented by the result types of the user tools.
+type UserResult interface {
+	UserTool() UserTool
+}
+
+// UserAnalysis is a result of the user tool, a user as listed by the user analysis.
+type UserAnalysis struct {
+	User
+}
+
+// UserTool returns UserToolUser.
+func (UserAnalysis) UserTool() UserTool { return UserToolUser }
+
+// UserBody is a user analysis query. The tool is set from the result type.
+type UserBody struct {
+	StartOffset int64
+	EndOffset   int64
+	Filters     []AnalysisFilter
+}
+
+// QueryUsers runs a user analysis with the tool of T and decodes the results as T, e.g.
+//
+//	users, _, err := tenable.QueryUsers[tenable.UserAnalysis](ctx, client.Analysis, tenable.UserBody{EndOffset: 100})
+//
+// Tenable API docs: https://docs.tenable.com/tenablesc/api/Analysis.htm
+func QueryUsers[T UserResult](ctx context.Context, s *AnalysisService, body UserBody) (*Envelope[ResultSet[T]], *Response, error) {
+	var zero T
+	req := newTypedBody(AnalysisUser, "", string(zero.UserTool()), body.StartOffset, body.EndOffset, body.Filters)
+	return post[ResultSet[T]](ctx, s.client, "/rest/analysis", req)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryUsers(t *testing.T) {
+	setup()
+	defer teardown()
+	testTypedAnalysis(t, `{"totalRecords":"1","results":[{"id":"7","username":"analyst","lastLogin":"1454350174"}]}`,
+		func(body map[string]interface{}) {
+			query := body["query"].(map[string]interface{})
+			if body["type"] != "user" || query["tool"] != "user" {
+				t.Errorf("Unexpected body %v", body)
+			}
+			if filters, ok := query["filters"].([]interface{}); !ok || len(filters) != 1 || filters[0].(map[string]interface{})["type"] != "user" {
+				t.Errorf("Expected a user filter, got %v", query["filters"])
+			}
+		})
+
+	filter, err := NewFilter("ip", OpEqual, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, _, err := QueryUsers[UserAnalysis](context.Background(), testClient.Analysis, UserBody{EndOffset: 50, Filters: []AnalysisFilter{filter}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := users.Response.Results; len(r) != 1 || r[0].ID != 7 || r[0].Username != "analyst" {
+		t.Errorf("Unexpected results %+v", r)
+	}
+	if filter.Type != "vuln" {
+		t.Errorf("Expected the filter of the caller to be unchanged, got type %q", filter.Type)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IDs of the key drivers of the Vulnerability Priority Rating (VPR).
+const (
+	VPRAgeOfVuln           = "age_of_vuln"
+	VPRCVSSv3ImpactScore   = "cvssV3_impactScore"
+	VPRExploitCodeMaturity = "exploit_code_maturity"
+	VPRProductCoverage     = "product_coverage"
+	VPRThreatIntensity     = "threat_intensity_last28"
+	VPRThreatRecency       = "threat_recency"
+	VPRThreatSources       = "threat_sources_last28"
+)
+
+// VPRContext is a key driver of the VPR of a vulnerability, e.g. its "Threat Intensity".
+type VPRContext struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Type is the type of Value, "string", "number" or "boolean"
+	Type  string   `json:"type,omitempty"`
+	Value VPRValue `json:"value"`
+}
+
+// VPRValue is the value of a VPR driver, a string, number or boolean.
+type VPRValue struct {
+	v interface{}
+}
+
+// NewVPRValue returns the VPRValue of a string, number or boolean.
+func NewVPRValue(v interface{}) VPRValue {
+	switch n := v.(type) {
+	case int:
+		v = float64(n)
+	case int64:
+		v = float64(n)
+	case Float:
+		v = float64(n)
+	}
+	return VPRValue{v: v}
+}
+
+// Interface returns the value as string, float64 or bool, or nil if it is not set.
+func (v VPRValue) Interface() interface{} {
+	return v.v
+}
+
+// String returns the text of v, e.g. "Very Low" or "5.9".
+func (v VPRValue) String() string {
+	switch x := v.v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return fmt.Sprint(v.v)
+}
+
+// Float returns v as number, parsing numeric strings. ok is false if v is not numeric.
+func (v VPRValue) Float() (f float64, ok bool) {
+	switch x := v.v.(type) {
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Bool returns v as boolean, parsing boolean strings. ok is false if v is not a boolean.
+func (v VPRValue) Bool() (b bool, ok bool) {
+	switch x := v.v.(type) {
+	case bool:
+		return x, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(x))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// MarshalJSON encodes v as its JSON string, number or boolean.
+func (v VPRValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.v)
+}
+
+// UnmarshalJSON decodes a JSON string, number, boolean or null into v.
+func (v *VPRValue) UnmarshalJSON(b []byte) error {
+	var x interface{}
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	switch x.(type) {
+	case nil, string, float64, bool:
+		v.v = x
+		return nil
+	}
+	return fmt.Errorf("tenable: invalid VPR value %s", b)
+}
+
+// VPRDrivers are the key drivers of a VPR. Tenable sends them JSON encoded as string,
+// e.g. "vprContext": "[]", they decode from that form as well as from a JSON array.
+type VPRDrivers []VPRContext
+
+// Driver returns the driver with the given ID, e.g. VPRThreatIntensity.
+func (d VPRDrivers) Driver(id string) (VPRContext, bool) {
+	for _, c := range d {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return VPRContext{}, false
+}
+
+// MarshalJSON encodes d as JSON string the way Tenable sends it.
+func (d VPRDrivers) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal([]VPRContext(d))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON decodes a JSON encoded string, an array or null into d.
+func (d *VPRDrivers) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return fmt.Errorf("tenable: invalid vprContext %s: %w", b, err)
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		*d = nil
+		return nil
+	}
+	var drivers []VPRContext
+	if err := json.Unmarshal([]byte(s), &drivers); err != nil {
+		return fmt.Errorf("tenable: invalid vprContext %s: %w", b, err)
+	}
+	*d = drivers
+	return nil
+}
+
+// VPRScorer is implemented by results with a VPR.
+type VPRScorer interface {
+	VPR() float64
+}
+
+// VPR returns the VPR of a, 0 if it has none.
+func (a Analysis) VPR() float64 { return float64(a.VPRScore) }
+
+// VPR returns the VPR of the plugin, 0 if it has none.
+func (s PluginSummary) VPR() float64 { return float64(s.VPRScore) }
+
+// VPR returns the VPR of the remediation, 0 if it has none.
+func (s RemediationSummary) VPR() float64 { return float64(s.VPRScore) }
+
+// SortByVPR sorts results by their VPR, highest first if dir is SortDesc.
+// Results of equal VPR keep their order.
+func SortByVPR[T VPRScorer](results []T, dir SortDirection) {
+	desc := strings.EqualFold(string(dir), string(SortDesc))
+	sort.SliceStable(results, func(i, j int) bool {
+		if desc {
+			return results[i].VPR() > results[j].VPR()
+		}
+		return results[i].VPR() < results[j].VPR()
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. 2022 All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenable
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const vprContext = `[{\"id\":\"age_of_vuln\",\"name\":\"Vulnerability Age\",\"type\":\"string\",\"value\":\"366 - 730 days\"},` +
+	`{\"id\":\"cvssV3_impactScore\",\"name\":\"CVSS v3 Impact Score\",\"type\":\"number\",\"value\":5.9},` +
+	`{\"id\":\"threat_intensity_last28\",\"name\":\"Threat Intensity\",\"type\":\"string\",\"value\":\"Very Low\"}]`
+
+func TestAnalysis_VPRContext(t *testing.T) {
+	var a Analysis
+	if err := json.Unmarshal([]byte(`{"pluginID":"1","vprScore":"6.7","vprContext":"`+vprContext+`"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.VPRScore != 6.7 || len(a.VPRContext) != 3 {
+		t.Fatalf("Unexpected VPR %v %+v", a.VPRScore, a.VPRContext)
+	}
+	intensity, ok := a.VPRContext.Driver(VPRThreatIntensity)
+	if !ok || intensity.Name != "Threat Intensity" || intensity.Value.String() != "Very Low" {
+		t.Errorf("Unexpected driver %+v", intensity)
+	}
+	impact, _ := a.VPRContext.Driver(VPRCVSSv3ImpactScore)
+	if f, ok := impact.Value.Float(); !ok || f != 5.9 || impact.Value.String() != "5.9" {
+		t.Errorf("Unexpected impact score %v", impact.Value.Interface())
+	}
+	if _, ok := a.VPRContext.Driver(VPRExploitCodeMaturity); ok {
+		t.Error("Expected no exploit code maturity driver")
+	}
+
+	// encoded as string the way Tenable sends it
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["vprContext"].(string); !ok || raw["vprScore"] != "6.7" {
+		t.Errorf("Unexpected encoding %s", b)
+	}
+	var again Analysis
+	if err := json.Unmarshal(b, &again); err != nil || len(again.VPRContext) != 3 {
+		t.Errorf("Round trip failed: %v %+v", err, again.VPRContext)
+	}
+}
+
+func TestVPRDrivers_UnmarshalJSON(t *testing.T) {
+	for _, data := range []string{`"[]"`, `""`, `null`, `[]`} {
+		var d VPRDrivers
+		if err := json.Unmarshal([]byte(data), &d); err != nil || len(d) != 0 {
+			t.Errorf("%s: unexpected %v %+v", data, err, d)
+		}
+	}
+	var d VPRDrivers
+	if err := json.Unmarshal([]byte(`[{"id":"x","type":"boolean","value":true}]`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := d[0].Value.Bool(); !ok || !b {
+		t.Errorf("Unexpected boolean %v", d[0].Value.Interface())
+	}
+	for _, invalid := range []string{`"[{"`, `"{}"`, `[{"id":"x","value":{}}]`} {
+		if err := json.Unmarshal([]byte(invalid), &d); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestSortByVPR(t *testing.T) {
+	results := []Analysis{{PluginID: 1, VPRScore: 4.4}, {PluginID: 2}, {PluginID: 3, VPRScore: 9.8}, {PluginID: 4, VPRScore: 4.4}}
+	SortByVPR(results, SortDesc)
+	for i, want := range []ID{3, 1, 4, 2} {
+		if results[i].PluginID != want {
+			t.Errorf("results[%d] is plugin %d, want %d", i, results[i].PluginID, want)
+		}
+	}
+	summaries := []PluginSummary{{PluginID: 1, VPRScore: 7}, {PluginID: 2, VPRScore: 3}}
+	SortByVPR(summaries, SortAsc)
+	if summaries[0].PluginID != 2 {
+		t.Errorf("Unexpected order %+v", summaries)
+	}
+}